@@ -1,5 +1,9 @@
 package ld
 
+import (
+	"sort"
+)
+
 // Compact operation compacts the given input using the context
 // according to the steps in the Compaction Algorithm:
 //
@@ -9,6 +13,9 @@ package ld
 // Returns an error if there was an error during compaction.
 func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element interface{},
 	compactArrays bool) (interface{}, error) {
+	// JSON-LD 1.1: a term definition of the active property may have a property-scoped context
+	propertyScopedContext, hasPropertyScopedContext := activeCtx.GetTermDefinition(activeProperty)["@context"]
+
 	// 2)
 	if elementList, isList := element.([]interface{}); isList {
 		// 2.1)
@@ -26,7 +33,9 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 			}
 		}
 		// 2.3)
-		if compactArrays && len(result) == 1 && activeCtx.GetContainer(activeProperty) == "" {
+		// JSON-LD 1.1: only @list and @set containers keep single values in an array
+		if compactArrays && len(result) == 1 && !activeCtx.HasContainerMapping(activeProperty, "@list") &&
+			!activeCtx.HasContainerMapping(activeProperty, "@set") {
 			return result[0], nil
 		}
 		// 2.4)
@@ -35,17 +44,62 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 
 	// 3)
 	if elem, isMap := element.(map[string]interface{}); isMap {
-		// 4
+		// JSON-LD 1.1: types are compacted using the context which was active
+		// before applying any scoped contexts
+		typeScopedCtx := activeCtx
+
+		// JSON-LD 1.1: a non-propagated context doesn't apply to new node objects,
+		// so it is reverted unless element is a value object or a node reference
 		_, containsValue := elem["@value"]
 		_, containsID := elem["@id"]
+		if activeCtx.previousContext != nil && !containsValue && !(containsID && len(elem) == 1) {
+			activeCtx = activeCtx.previousContext
+		}
+
+		// JSON-LD 1.1: apply the property-scoped context
+		if hasPropertyScopedContext {
+			newCtx, err := activeCtx.parse(propertyScopedContext, make([]string, 0), true, true, true)
+			if err != nil {
+				return nil, err
+			}
+			activeCtx = newCtx
+		}
+
+		// 4
 		if containsValue || containsID {
 			compactedValue := activeCtx.CompactValue(activeProperty, elem)
 			_, isMap := compactedValue.(map[string]interface{})
 			_, isList := compactedValue.([]interface{})
-			if !(isMap || isList) {
+			// JSON-LD 1.1: JSON literals of terms with @json type mapping may be objects or arrays
+			if !(isMap || isList) || activeCtx.GetTypeMapping(activeProperty) == "@json" {
 				return compactedValue, nil
 			}
 		}
+
+		// JSON-LD 1.1: apply type-scoped contexts in lexicographical order of the compacted types
+		if typeVal, hasType := elem["@type"]; hasType {
+			typeList, isList := typeVal.([]interface{})
+			if !isList {
+				typeList = []interface{}{typeVal}
+			}
+			types := make([]string, 0, len(typeList))
+			for _, t := range typeList {
+				if typeStr, isString := t.(string); isString {
+					types = append(types, activeCtx.CompactIri(typeStr, nil, true, false))
+				}
+			}
+			sort.Strings(types)
+			for _, t := range types {
+				if typeScopedContext, hasContext := typeScopedCtx.GetTermDefinition(t)["@context"]; hasContext {
+					newCtx, err := activeCtx.parse(typeScopedContext, make([]string, 0), false, false, true)
+					if err != nil {
+						return nil, err
+					}
+					activeCtx = newCtx
+				}
+			}
+		}
+
 		// 5)
 		insideReverse := activeProperty == "@reverse"
 
@@ -59,26 +113,34 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 			if expandedProperty == "@id" || expandedProperty == "@type" {
 				var compactedValue interface{}
 
+				// 7.1.3)
+				alias := activeCtx.CompactIri(expandedProperty, nil, true, false)
+
+				valueCtx := activeCtx
+				if expandedProperty == "@type" {
+					valueCtx = typeScopedCtx
+				}
+
 				// 7.1.1)
 				if expandedValueStr, isString := expandedValue.(string); isString {
-					compactedValue = activeCtx.CompactIri(expandedValueStr, nil, expandedProperty == "@type", false)
+					compactedValue = valueCtx.CompactIri(expandedValueStr, nil, expandedProperty == "@type", false)
 				} else { // 7.1.2)
 					types := make([]interface{}, 0)
 					// 7.1.2.2)
 					for _, expandedTypeVal := range expandedValue.([]interface{}) {
 						expandedType := expandedTypeVal.(string)
-						types = append(types, activeCtx.CompactIri(expandedType, nil, true, false))
+						types = append(types, valueCtx.CompactIri(expandedType, nil, true, false))
 					}
 					// 7.1.2.3)
-					if len(types) == 1 {
+					// JSON-LD 1.1: keep the array if the alias has a @set container
+					typeAsSet := activeCtx.processingMode(1.1) && activeCtx.HasContainerMapping(alias, "@set")
+					if len(types) == 1 && !typeAsSet {
 						compactedValue = types[0]
 					} else {
 						compactedValue = types
 					}
 				}
 
-				// 7.1.3)
-				alias := activeCtx.CompactIri(expandedProperty, nil, true, false)
 				// 7.1.4)
 				result[alias] = compactedValue
 				continue
@@ -96,7 +158,7 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 					if activeCtx.IsReverseProperty(property) {
 						// 7.2.2.1.1)
 						valueList, isList := value.([]interface{})
-						if (activeCtx.GetContainer(property) == "@set" || !compactArrays) && !isList {
+						if (activeCtx.HasContainerMapping(property, "@set") || !compactArrays) && !isList {
 							result[property] = []interface{}{value}
 						}
 						// 7.2.2.1.2)
@@ -130,10 +192,10 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 				continue
 			}
 			// 7.3)
-			if expandedProperty == "@index" && activeCtx.GetContainer(activeProperty) == "@index" {
+			if expandedProperty == "@index" && activeCtx.HasContainerMapping(activeProperty, "@index") {
 				continue
 			} else if expandedProperty == "@index" || expandedProperty == "@value" ||
-				expandedProperty == "@language" { // 7.4)
+				expandedProperty == "@language" || expandedProperty == "@direction" { // 7.4)
 				// 7.4.1)
 				alias := activeCtx.CompactIri(expandedProperty, nil, true, false)
 				// 7.4.2)
@@ -149,13 +211,17 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 			if isList && len(expandedValueList) == 0 {
 				// 7.5.1)
 				itemActiveProperty := activeCtx.CompactIri(expandedProperty, expandedValue, true, insideReverse)
+				nestResult, err := getNestResult(activeCtx, itemActiveProperty, result)
+				if err != nil {
+					return nil, err
+				}
 				// 7.5.2)
-				itemActivePropertyVal, present := result[itemActiveProperty]
+				itemActivePropertyVal, present := nestResult[itemActiveProperty]
 				if !present {
-					result[itemActiveProperty] = make([]interface{}, 0)
+					nestResult[itemActiveProperty] = make([]interface{}, 0)
 				} else {
 					if _, isList := itemActivePropertyVal.([]interface{}); !isList {
-						result[itemActiveProperty] = []interface{}{itemActivePropertyVal}
+						nestResult[itemActiveProperty] = []interface{}{itemActivePropertyVal}
 					}
 				}
 			}
@@ -164,22 +230,41 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 			for _, expandedItem := range expandedValueList {
 				// 7.6.1)
 				itemActiveProperty := activeCtx.CompactIri(expandedProperty, expandedItem, true, insideReverse)
+				nestResult, err := getNestResult(activeCtx, itemActiveProperty, result)
+				if err != nil {
+					return nil, err
+				}
 				// 7.6.2)
-				container := activeCtx.GetContainer(itemActiveProperty)
+				// JSON-LD 1.1: values are kept in arrays if the container mapping includes @set
+				mapAsArray := activeCtx.HasContainerMapping(itemActiveProperty, "@set") ||
+					activeCtx.HasContainerMapping(itemActiveProperty, "@list") ||
+					expandedProperty == "@list" || expandedProperty == "@graph"
+				// compactArrays only affects values of maps in json-ld-1.1 mode,
+				// JSON-LD 1.0 applies it to plain property values only
+				if !compactArrays && activeCtx.processingMode(1.1) {
+					mapAsArray = true
+				}
+				asArray := !compactArrays || mapAsArray
 
 				// get @list value if appropriate
 				expandedItemMap, isMap := expandedItem.(map[string]interface{})
 				list, containsList := expandedItemMap["@list"]
 				isList := isMap && containsList
+				isGraph := IsGraph(expandedItem)
 
 				// 7.6.3)
 				var elementToCompact interface{}
 				if isList {
 					elementToCompact = list
+				} else if isGraph {
+					elementToCompact = expandedItemMap["@graph"]
 				} else {
 					elementToCompact = expandedItem
 				}
-				compactedItem, _ := api.Compact(activeCtx, itemActiveProperty, elementToCompact, compactArrays)
+				compactedItem, err := api.Compact(activeCtx, itemActiveProperty, elementToCompact, compactArrays)
+				if err != nil {
+					return nil, err
+				}
 
 				// 7.6.4)
 				if isList {
@@ -189,7 +274,7 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 						compactedItem = []interface{}{compactedItem}
 					}
 					// 7.6.4.2)
-					if container != "@list" {
+					if !activeCtx.HasContainerMapping(itemActiveProperty, "@list") {
 						// 7.6.4.2.1)
 						wrapper := make(map[string]interface{})
 						// TODO: SPEC: no mention of vocab = true
@@ -201,73 +286,131 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 							// TODO: SPEC: no mention of vocab = true
 							wrapper[activeCtx.CompactIri("@index", nil, true, false)] = indexVal
 						}
-					} else if _, present := result[itemActiveProperty]; present { // 7.6.4.3)
+					} else if _, present := nestResult[itemActiveProperty]; present { // 7.6.4.3)
 						return nil, NewJsonLdError(CompactionToListOfLists,
 							"There cannot be two list objects associated with an active property that has a container mapping")
 					}
 				}
-				// 7.6.5)
-				if container == "@language" || container == "@index" {
-					// 7.6.5.1)
 
-					var mapObject map[string]interface{}
-					if v, present := result[itemActiveProperty]; present {
-						mapObject = v.(map[string]interface{})
+				if isGraph {
+					// JSON-LD 1.1: graph objects
+					isGraphContainer := activeCtx.HasContainerMapping(itemActiveProperty, "@graph")
+					if isGraphContainer && activeCtx.HasContainerMapping(itemActiveProperty, "@id") {
+						// graph objects are indexed by their @id
+						mapKey := activeCtx.CompactIri("@none", nil, true, false)
+						if idVal, containsID := expandedItemMap["@id"]; containsID {
+							mapKey = activeCtx.CompactIri(idVal.(string), nil, false, false)
+						}
+						mapObject := getMapObject(nestResult, itemActiveProperty)
+						addCompactedValue(mapObject, mapKey, compactedItem, mapAsArray)
+					} else if isGraphContainer && activeCtx.HasContainerMapping(itemActiveProperty, "@index") &&
+						IsSimpleGraph(expandedItem) {
+						// graph objects are indexed by their @index
+						mapKey := activeCtx.CompactIri("@none", nil, true, false)
+						if indexVal, containsIndex := expandedItemMap["@index"]; containsIndex {
+							mapKey = indexVal.(string)
+						}
+						mapObject := getMapObject(nestResult, itemActiveProperty)
+						addCompactedValue(mapObject, mapKey, compactedItem, mapAsArray)
+					} else if isGraphContainer && IsSimpleGraph(expandedItem) {
+						// several nodes would be interpreted as different graphs,
+						// so they are kept together using @included
+						if compactedList, isList := compactedItem.([]interface{}); isList && len(compactedList) > 1 {
+							compactedItem = map[string]interface{}{
+								activeCtx.CompactIri("@included", nil, true, false): compactedItem,
+							}
+						}
+						addCompactedValue(nestResult, itemActiveProperty, compactedItem, asArray)
 					} else {
-						mapObject = make(map[string]interface{})
-						result[itemActiveProperty] = mapObject
+						// the graph object can't be expressed using the container mapping
+						wrapper := map[string]interface{}{
+							activeCtx.CompactIri("@graph", nil, true, false): compactedItem,
+						}
+						if idVal, containsID := expandedItemMap["@id"]; containsID {
+							wrapper[activeCtx.CompactIri("@id", nil, true, false)] =
+								activeCtx.CompactIri(idVal.(string), nil, false, false)
+						}
+						if indexVal, containsIndex := expandedItemMap["@index"]; containsIndex {
+							wrapper[activeCtx.CompactIri("@index", nil, true, false)] = indexVal
+						}
+						addCompactedValue(nestResult, itemActiveProperty, wrapper, asArray)
 					}
+				} else if activeCtx.HasContainerMapping(itemActiveProperty, "@language") ||
+					activeCtx.HasContainerMapping(itemActiveProperty, "@index") ||
+					activeCtx.HasContainerMapping(itemActiveProperty, "@id") ||
+					activeCtx.HasContainerMapping(itemActiveProperty, "@type") { // 7.6.5)
+					// 7.6.5.1)
+					mapObject := getMapObject(nestResult, itemActiveProperty)
 
 					// 7.6.5.2)
+					var mapKey interface{}
 					compactedItemMap, isMap := compactedItem.(map[string]interface{})
-					compactedItemValue, containsValue := compactedItemMap["@value"]
-					if container == "@language" && isMap && containsValue {
-						compactedItem = compactedItemValue
+					if activeCtx.HasContainerMapping(itemActiveProperty, "@language") {
+						if compactedItemValue, containsValue := compactedItemMap["@value"]; isMap && containsValue {
+							compactedItem = compactedItemValue
+						}
+						mapKey = expandedItemMap["@language"]
+					} else if indexKey, isPropertyIndex :=
+						activeCtx.GetTermDefinition(itemActiveProperty)["@index"].(string); isPropertyIndex {
+						// JSON-LD 1.1: property-valued index maps are keyed by the first
+						// string value of the index property
+						containerKey := activeCtx.CompactIri(indexKey, nil, true, false)
+						indexValues, isList := compactedItemMap[containerKey].([]interface{})
+						if !isList && compactedItemMap[containerKey] != nil {
+							indexValues = []interface{}{compactedItemMap[containerKey]}
+						}
+						if len(indexValues) > 0 {
+							if firstValue, isString := indexValues[0].(string); isString {
+								mapKey = firstValue
+								delete(compactedItemMap, containerKey)
+								if len(indexValues) > 1 {
+									addCompactedValue(compactedItemMap, containerKey, indexValues[1:], false)
+								}
+							}
+						}
+					} else if activeCtx.HasContainerMapping(itemActiveProperty, "@index") {
+						mapKey = expandedItemMap["@index"]
+					} else if activeCtx.HasContainerMapping(itemActiveProperty, "@id") {
+						// JSON-LD 1.1: id maps are keyed by the compacted @id of the items
+						idAlias := activeCtx.CompactIri("@id", nil, true, false)
+						mapKey = compactedItemMap[idAlias]
+						delete(compactedItemMap, idAlias)
+					} else {
+						// JSON-LD 1.1: type maps are keyed by the first compacted @type of the items
+						typeAlias := activeCtx.CompactIri("@type", nil, true, false)
+						typeList, isList := compactedItemMap[typeAlias].([]interface{})
+						if !isList && compactedItemMap[typeAlias] != nil {
+							typeList = []interface{}{compactedItemMap[typeAlias]}
+						}
+						delete(compactedItemMap, typeAlias)
+						if len(typeList) > 0 {
+							mapKey = typeList[0]
+						}
+						if len(typeList) > 1 {
+							addCompactedValue(compactedItemMap, typeAlias, typeList[1:], false)
+						}
+						// a node reference is compacted according to the type mapping of the term
+						if idVal, containsID := expandedItemMap["@id"]; containsID && len(compactedItemMap) == 1 {
+							if _, onlyID := compactedItemMap[activeCtx.CompactIri("@id", nil, true, false)]; onlyID {
+								compactedItem, err = api.Compact(activeCtx, itemActiveProperty,
+									map[string]interface{}{"@id": idVal}, compactArrays)
+								if err != nil {
+									return nil, err
+								}
+							}
+						}
 					}
 
 					// 7.6.5.3)
-					mapKey := expandedItemMap[container].(string)
-					// 7.6.5.4)
-					mapValue, hasMapKey := mapObject[mapKey]
+					// JSON-LD 1.1: values without a key use the @none key
+					mapKeyStr, hasMapKey := mapKey.(string)
 					if !hasMapKey {
-						mapObject[mapKey] = compactedItem
-					} else {
-						mapValueList, isList := mapValue.([]interface{})
-						var tmp []interface{}
-						if !isList {
-							tmp = []interface{}{mapValue}
-						} else {
-							tmp = mapValueList
-						}
-						tmp = append(tmp, compactedItem)
-						mapObject[mapKey] = tmp
+						mapKeyStr = activeCtx.CompactIri("@none", nil, true, false)
 					}
+					// 7.6.5.4)
+					addCompactedValue(mapObject, mapKeyStr, compactedItem, mapAsArray)
 				} else { // 7.6.6)
-					// 7.6.6.1)
-					_, isList := compactedItem.([]interface{})
-					check := (!compactArrays || container == "@set" || container == "@list" ||
-						expandedProperty == "@list" || expandedProperty == "@graph") && !isList
-					if check {
-						compactedItem = []interface{}{compactedItem}
-					}
-					// 7.6.6.2)
-					itemActivePropertyVal, present := result[itemActiveProperty]
-					if !present {
-						result[itemActiveProperty] = compactedItem
-					} else {
-						itemActivePropertyValueList, isList := itemActivePropertyVal.([]interface{})
-						if !isList {
-							itemActivePropertyValueList = []interface{}{itemActivePropertyVal}
-							result[itemActiveProperty] = itemActivePropertyValueList
-						}
-						compactedItemList, isList := compactedItem.([]interface{})
-						if isList {
-							itemActivePropertyValueList = append(itemActivePropertyValueList, compactedItemList...)
-						} else {
-							itemActivePropertyValueList = append(itemActivePropertyValueList, compactedItem)
-						}
-						result[itemActiveProperty] = itemActivePropertyValueList
-					}
+					addCompactedValue(nestResult, itemActiveProperty, compactedItem, asArray)
 				}
 			}
 		}
@@ -277,3 +420,65 @@ func (api *JsonLdApi) Compact(activeCtx *Context, activeProperty string, element
 	// 2)
 	return element, nil
 }
+
+// getMapObject returns the map (such as a language or an index map) which holds the values
+// of the given property in result, creating it if necessary.
+func getMapObject(result map[string]interface{}, property string) map[string]interface{} {
+	mapObject, isMap := result[property].(map[string]interface{})
+	if !isMap {
+		mapObject = make(map[string]interface{})
+		result[property] = mapObject
+	}
+	return mapObject
+}
+
+// addCompactedValue adds a compacted value to the given key of obj. The items of an array value
+// are added one by one. The key holds an array if asArray is true or if it has more than one value.
+func addCompactedValue(obj map[string]interface{}, key string, value interface{}, asArray bool) {
+	if valueList, isList := value.([]interface{}); isList {
+		if _, present := obj[key]; !present && (asArray || len(valueList) != 1) {
+			obj[key] = make([]interface{}, 0)
+		}
+		for _, item := range valueList {
+			addCompactedValue(obj, key, item, asArray)
+		}
+		return
+	}
+	if existing, present := obj[key]; present {
+		existingList, isList := existing.([]interface{})
+		if !isList {
+			existingList = []interface{}{existing}
+		}
+		obj[key] = append(existingList, value)
+	} else if asArray {
+		obj[key] = []interface{}{value}
+	} else {
+		obj[key] = value
+	}
+}
+
+// getNestResult returns the object the values of itemActiveProperty should be added to.
+// This is result itself unless the term has a nest value (JSON-LD 1.1), in which case
+// the values go to the nested object under the nest term.
+func getNestResult(activeCtx *Context, itemActiveProperty string, result map[string]interface{}) (map[string]interface{}, error) {
+	nestVal, hasNest := activeCtx.GetTermDefinition(itemActiveProperty)["@nest"]
+	if !hasNest {
+		return result, nil
+	}
+	nestTerm := nestVal.(string)
+	if nestTerm != "@nest" {
+		expandedNest, err := activeCtx.ExpandIri(nestTerm, false, true, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if expandedNest != "@nest" {
+			return nil, NewJsonLdError(InvalidNestValue, "nest term must expand to @nest: "+nestTerm)
+		}
+	}
+	nestResult, isMap := result[nestTerm].(map[string]interface{})
+	if !isMap {
+		nestResult = make(map[string]interface{})
+		result[nestTerm] = nestResult
+	}
+	return nestResult, nil
+}
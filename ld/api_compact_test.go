@@ -0,0 +1,44 @@
+package ld_test
+
+import (
+	"testing"
+
+	. "github.com/kazarena/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactArraysLanguageMap(t *testing.T) {
+	proc := NewJsonLdProcessor()
+
+	input := map[string]interface{}{
+		"@id": "http://example.org/a",
+		"http://example.org/label": map[string]interface{}{
+			"@value":    "a",
+			"@language": "en",
+		},
+	}
+	context := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"label": map[string]interface{}{
+				"@id":        "http://example.org/label",
+				"@container": "@language",
+			},
+		},
+	}
+
+	// without compactArrays the result is always wrapped in @graph
+	// JSON-LD 1.0 keeps single values of maps as they are
+	opts := NewJsonLdOptions("")
+	opts.CompactArrays = false
+	compacted, err := proc.Compact(input, context, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"en": "a"}, compacted["@graph"].([]interface{})[0].(map[string]interface{})["label"])
+
+	// json-ld-1.1 puts them in arrays
+	opts = NewJsonLdOptions("")
+	opts.CompactArrays = false
+	opts.ProcessingMode = JsonLd_1_1
+	compacted, err = proc.Compact(input, context, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"en": []interface{}{"a"}}, compacted["@graph"].([]interface{})[0].(map[string]interface{})["label"])
+}
@@ -2,6 +2,7 @@ package ld
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -12,12 +13,22 @@ import (
 // Returns the expanded JSON-LD object.
 // Returns an error if there was an error during expansion.
 func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element interface{}, opts *JsonLdOptions) (interface{}, error) {
+	return api.expand(activeCtx, activeProperty, element, opts, false)
+}
+
+// expand implements the Expansion algorithm. fromMap is true if element is a value
+// of an index map: such values are still in scope of a type-scoped context.
+func (api *JsonLdApi) expand(activeCtx *Context, activeProperty string, element interface{}, opts *JsonLdOptions,
+	fromMap bool) (interface{}, error) {
 	frameExpansion := opts.ProcessingMode == JsonLd_1_1_Frame
 	// 1)
 	if element == nil {
 		return nil, nil
 	}
 
+	// JSON-LD 1.1: a term definition of the active property may have a property-scoped context
+	propertyScopedContext, hasPropertyScopedContext := activeCtx.GetTermDefinition(activeProperty)["@context"]
+
 	// 3)
 	switch elem := element.(type) {
 	case []interface{}:
@@ -26,12 +37,12 @@ func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element
 		// 3.2)
 		for _, item := range elem {
 			// 3.2.1)
-			v, err := api.Expand(activeCtx, activeProperty, item, opts)
+			v, err := api.expand(activeCtx, activeProperty, item, opts, fromMap)
 			if err != nil {
 				return nil, err
 			}
 			// 3.2.2)
-			if activeProperty == "@list" || activeCtx.GetContainer(activeProperty) == "@list" {
+			if activeProperty == "@list" || activeCtx.HasContainerMapping(activeProperty, "@list") {
 				_, isList := v.([]interface{})
 				vMap, isMap := v.(map[string]interface{})
 				_, mapContainsList := vMap["@list"]
@@ -56,6 +67,34 @@ func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element
 
 	case map[string]interface{}:
 
+		// JSON-LD 1.1: a non-propagated context doesn't apply to new node objects,
+		// so it is reverted unless element is a value object or a node reference
+		if activeCtx.previousContext != nil && !fromMap {
+			revert := true
+			for key := range elem {
+				expandedKey, err := activeCtx.ExpandIri(key, false, true, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+				if expandedKey == "@value" || (expandedKey == "@id" && len(elem) == 1) {
+					revert = false
+					break
+				}
+			}
+			if revert {
+				activeCtx = activeCtx.previousContext
+			}
+		}
+
+		// JSON-LD 1.1: apply the property-scoped context
+		if hasPropertyScopedContext {
+			newCtx, err := activeCtx.parse(propertyScopedContext, make([]string, 0), true, true, true)
+			if err != nil {
+				return nil, err
+			}
+			activeCtx = newCtx
+		}
+
 		// 4)
 		// 5)
 		if ctx, hasContext := elem["@context"]; hasContext {
@@ -65,404 +104,64 @@ func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element
 			}
 			activeCtx = newCtx
 		}
-		// 6)
-		resultMap := make(map[string]interface{})
-		// 7)
+
+		// JSON-LD 1.1: type-scoped contexts. The types themselves are expanded
+		// using the context which was active before applying them.
+		// The input type (the last type of the first @type entry) is used to detect JSON literals.
+		typeScopedCtx := activeCtx
+		inputType := ""
 		for _, key := range GetOrderedKeys(elem) {
-			value := elem[key]
-			// 7.1)
-			if key == "@context" {
-				continue
-			}
-			// 7.2)
-			expandedProperty, err := activeCtx.ExpandIri(key, false, true, nil, nil)
+			expandedKey, err := activeCtx.ExpandIri(key, false, true, nil, nil)
 			if err != nil {
 				return nil, err
 			}
-			var expandedValue interface{}
-			// 7.3)
-			if expandedProperty == "" || (!strings.Contains(expandedProperty, ":") && !IsKeyword(expandedProperty)) {
+			if expandedKey != "@type" {
 				continue
 			}
-			// 7.4)
-			if IsKeyword(expandedProperty) {
-				// 7.4.1)
-				if activeProperty == "@reverse" {
-					return nil, NewJsonLdError(InvalidReversePropertyMap,
-						"a keyword cannot be used as a @reverse propery")
-				}
-				// 7.4.2)
-				if _, containsKey := resultMap[expandedProperty]; containsKey {
-					return nil, NewJsonLdError(CollidingKeywords, expandedProperty+" already exists in result")
-				}
-				// 7.4.3)
-				if expandedProperty == "@id" {
-					valueStr, isString := value.(string)
-					if isString {
-						expandedValue, err = activeCtx.ExpandIri(valueStr, true, false, nil, nil)
-						if err != nil {
-							return nil, err
-						}
-					} else if frameExpansion {
-						if valueMap, isMap := value.(map[string]interface{}); isMap {
-							if len(valueMap) != 0 {
-								return nil, NewJsonLdError(InvalidIDValue, "@id value must be a an empty object for framing")
-							}
-							expandedValue = value
-						} else if valueList, isList := value.([]interface{}); isList {
-							expandedValue := make([]string, 0)
-							for _, v := range valueList {
-								vString, isString := v.(string)
-								if !isString {
-									return nil, NewJsonLdError(InvalidIDValue, "@id value must be a string, an array of strings or an empty dictionary")
-								}
-								v, err := activeCtx.ExpandIri(vString, true, true, nil, nil)
-								if err != nil {
-									return nil, err
-								}
-								expandedValue = append(expandedValue, v)
-							}
-						} else {
-							return nil, NewJsonLdError(InvalidIDValue, "value of @id must be a string, an array of strings or an empty dictionary")
-						}
-					} else {
-						return nil, NewJsonLdError(InvalidIDValue, "value of @id must be a string")
-					}
-				} else if expandedProperty == "@type" { // 7.4.4)
-					switch v := value.(type) {
-					case []interface{}:
-						var expandedValueList []interface{}
-						for _, listElem := range v {
-							listElemStr, isString := listElem.(string)
-							if !isString {
-								return nil, NewJsonLdError(InvalidTypeValue,
-									"@type value must be a string or array of strings")
-							}
-							newVal, err := activeCtx.ExpandIri(listElemStr, true, true, nil, nil)
-							if err != nil {
-								return nil, err
-							}
-							expandedValueList = append(expandedValueList, newVal)
-						}
-						expandedValue = expandedValueList
-					case string:
-						expandedValue, err = activeCtx.ExpandIri(v, true, true, nil, nil)
-						if err != nil {
-							return nil, err
-						}
-					case map[string]interface{}:
-						if len(v) != 0 {
-							return nil, NewJsonLdError(InvalidTypeValue,
-								"@type value must be a an empty object for framing")
-						}
-						expandedValue = value
-					default:
-						return nil, NewJsonLdError(InvalidTypeValue, "@type value must be a string or array of strings")
-					}
-				} else if expandedProperty == "@graph" { // 7.4.5)
-					expandedValue, _ = api.Expand(activeCtx, "@graph", value, opts)
-				} else if expandedProperty == "@value" { // 7.4.6)
-					_, isMap := value.(map[string]interface{})
-					_, isList := value.([]interface{})
-					if value != nil && (isMap || isList) {
-						return nil, NewJsonLdError(InvalidValueObjectValue, "value of "+
-							expandedProperty+" must be a scalar or null")
-					}
-					expandedValue = value
-					if expandedValue == nil {
-						resultMap["@value"] = nil
-						continue
-					}
-				} else if expandedProperty == "@language" { // 7.4.7)
-					valueStr, isString := value.(string)
-					if !isString {
-						return nil, NewJsonLdError(InvalidLanguageTaggedString, "Value of "+
-							expandedProperty+" must be a string")
-					}
-					expandedValue = strings.ToLower(valueStr)
-				} else if expandedProperty == "@index" { // 7.4.8)
-					_, isString := value.(string)
-					if !isString {
-						return nil, NewJsonLdError(InvalidIndexValue, "Value of "+
-							expandedProperty+" must be a string")
-					}
-					expandedValue = value
-				} else if expandedProperty == "@list" { // 7.4.9)
-					// 7.4.9.1)
-					if activeProperty == "" || activeProperty == "@graph" {
-						continue
-					}
-					// 7.4.9.2)
-					expandedValue, _ = api.Expand(activeCtx, activeProperty, value, opts)
-
-					// NOTE: step not in the spec yet
-					expandedValueList, isList := expandedValue.([]interface{})
-					if !isList {
-						expandedValueList = []interface{}{expandedValue}
-						expandedValue = expandedValueList
-					}
-
-					// 7.4.9.3)
-					for _, o := range expandedValueList {
-						oMap, isMap := o.(map[string]interface{})
-						if _, containsList := oMap["@list"]; isMap && containsList {
-							return nil, NewJsonLdError(ListOfLists, "A list may not contain another list")
-						}
-					}
-				} else if expandedProperty == "@set" { // 7.4.10)
-					expandedValue, _ = api.Expand(activeCtx, activeProperty, value, opts)
-				} else if expandedProperty == "@reverse" { // 7.4.11)
-					_, isMap := value.(map[string]interface{})
-					if !isMap {
-						return nil, NewJsonLdError(InvalidReverseValue, "@reverse value must be an object")
-					}
-					// 7.4.11.1)
-					expandedValue, err = api.Expand(activeCtx, "@reverse", value, opts)
-					if err != nil {
-						return nil, err
-					}
-
-					// NOTE: algorithm assumes the result is a map
-					// 7.4.11.2)
-					reverseValue, containsReverse := expandedValue.(map[string]interface{})["@reverse"]
-					if containsReverse {
-						for property, item := range reverseValue.(map[string]interface{}) {
-							// 7.4.11.2.1)
-							var propertyList []interface{}
-							if propertyValue, containsProperty := resultMap[property]; containsProperty {
-								propertyList = propertyValue.([]interface{})
-							} else {
-								propertyList = make([]interface{}, 0)
-								resultMap[property] = propertyList
-							}
-							// 7.4.11.2.2)
-							if itemList, isList := item.([]interface{}); isList {
-								propertyList = append(propertyList, itemList...)
-							} else {
-								propertyList = append(propertyList, item)
-							}
-							resultMap[property] = propertyList
-						}
-					}
-					// 7.4.11.3)
-					expandedValueMap := expandedValue.(map[string]interface{})
-					var maxSize int
-					if containsReverse {
-						maxSize = 1
-					} else {
-						maxSize = 0
-					}
-					if len(expandedValueMap) > maxSize {
-						var reverseMap map[string]interface{}
-						if reverseValue, containsReverse := resultMap["@reverse"]; containsReverse {
-							// 7.4.11.3.2)
-							reverseMap = reverseValue.(map[string]interface{})
-						} else {
-							// 7.4.11.3.1)
-							reverseMap = make(map[string]interface{})
-							resultMap["@reverse"] = reverseMap
-						}
-
-						// 7.4.11.3.3)
-						for property, propertyValue := range expandedValueMap {
-							if property == "@reverse" {
-								continue
-							}
-							// 7.4.11.3.3.1)
-							items := propertyValue.([]interface{})
-							for _, item := range items {
-								// 7.4.11.3.3.1.1)
-								itemMap := item.(map[string]interface{})
-								_, containsValue := itemMap["@value"]
-								_, containsList := itemMap["@list"]
-								if containsValue || containsList {
-									return nil, NewJsonLdError(InvalidReversePropertyValue, nil)
-								}
-								// 7.4.11.3.3.1.2)
-								var propertyValueList []interface{}
-								propertyValue, containsProperty := reverseMap[property]
-								if containsProperty {
-									propertyValueList = propertyValue.([]interface{})
-								} else {
-									propertyValueList = make([]interface{}, 0)
-									reverseMap[property] = propertyValueList
-								}
-								// 7.4.11.3.3.1.3)
-								reverseMap[property] = append(propertyValueList, item)
-							}
-						}
-					}
-					// 7.4.11.4)
-					continue
-				} else if expandedProperty == "@explicit" || // TODO: SPEC no mention of @explicit etc in spec
-					expandedProperty == "@default" ||
-					expandedProperty == "@embed" ||
-					expandedProperty == "@embedChildren" ||
-					expandedProperty == "@omitDefault" {
-					expandedValue, _ = api.Expand(activeCtx, expandedProperty, value, opts)
-				}
-				// 7.4.12)
-				if expandedValue != nil {
-					resultMap[expandedProperty] = expandedValue
-				}
-				// 7.4.13)
-				continue
-			} else {
-				valueMap, isMap := value.(map[string]interface{})
-				// 7.5
-				if activeCtx.GetContainer(key) == "@language" && isMap {
-					// 7.5.1)
-					var expandedValueList []interface{}
-					// 7.5.2)
-					for _, language := range GetOrderedKeys(valueMap) {
-						languageValue := valueMap[language]
-						// 7.5.2.1)
-						languageList, isList := languageValue.([]interface{})
-						if !isList {
-							languageList = []interface{}{languageValue}
-						}
-						// 7.5.2.2)
-						for _, item := range languageList {
-							// 7.5.2.2.1)
-							if _, isString := item.(string); !isString {
-								return nil, NewJsonLdError(InvalidLanguageMapValue, "Expected "+
-									fmt.Sprintf("%v", item)+" to be a string")
-							}
-							// 7.5.2.2.2)
-							expandedValueList = append(expandedValueList, map[string]interface{}{
-								"@value":    item,
-								"@language": strings.ToLower(language),
-							})
-						}
-					}
-					expandedValue = expandedValueList
-				} else if activeCtx.GetContainer(key) == "@index" && isMap { // 7.6)
-					// 7.6.1)
-					var expandedValueList []interface{}
-					// 7.6.2)
-					for _, index := range GetOrderedKeys(valueMap) {
-						indexValue := valueMap[index]
-						// 7.6.2.1)
-						indexValueList, isList := indexValue.([]interface{})
-						if !isList {
-							indexValueList = []interface{}{indexValue}
-						}
-						// 7.6.2.2)
-						indexValue, _ = api.Expand(activeCtx, key, indexValueList, opts)
-						// 7.6.2.3)
-						for _, itemValue := range indexValue.([]interface{}) {
-							item := itemValue.(map[string]interface{})
-							// 7.6.2.3.1)
-							if _, containsKey := item["@index"]; !containsKey {
-								item["@index"] = index
-							}
-							// 7.6.2.3.2)
-							expandedValueList = append(expandedValueList, item)
-						}
-					}
-					expandedValue = expandedValueList
-				} else {
-					// 7.7)
-					expandedValue, err = api.Expand(activeCtx, key, value, opts)
-					if err != nil {
-						return nil, err
-					}
-				}
+			typeList, isList := elem[key].([]interface{})
+			if !isList {
+				typeList = []interface{}{elem[key]}
 			}
-
-			// 7.8)
-			if expandedValue == nil {
+			if len(typeList) == 0 {
 				continue
 			}
-			// 7.9)
-			if activeCtx.GetContainer(key) == "@list" {
-				expandedValueMap, isMap := expandedValue.(map[string]interface{})
-				_, containsList := expandedValueMap["@list"]
-				if !isMap || !containsList {
-					newExpandedValue := make(map[string]interface{}, 1)
-					_, isList := expandedValue.([]interface{})
-					if !isList {
-						newExpandedValue["@list"] = []interface{}{expandedValue}
-					} else {
-						newExpandedValue["@list"] = expandedValue
-					}
-					expandedValue = newExpandedValue
+			if lastType, isString := typeList[len(typeList)-1].(string); isString && inputType == "" {
+				inputType, err = typeScopedCtx.ExpandIri(lastType, false, true, nil, nil)
+				if err != nil {
+					return nil, err
 				}
 			}
-			// 7.10)
-			if activeCtx.IsReverseProperty(key) {
-				var reverseMap map[string]interface{}
-				if reverseValue, containsReverse := resultMap["@reverse"]; containsReverse {
-					// 7.10.2)
-					reverseMap = reverseValue.(map[string]interface{})
-				} else {
-					// 7.10.1)
-					reverseMap = make(map[string]interface{})
-					resultMap["@reverse"] = reverseMap
-				}
-
-				// 7.10.3)
-				expandedValueList, isList := expandedValue.([]interface{})
-				if !isList {
-					expandedValueList = []interface{}{expandedValue}
-					expandedValue = expandedValueList
+			types := make([]string, 0, len(typeList))
+			for _, t := range typeList {
+				if typeStr, isString := t.(string); isString {
+					types = append(types, typeStr)
 				}
-				// 7.10.4)
-				for _, item := range expandedValueList {
-
-					// 7.10.4.2)
-					var expandedPropertyList []interface{}
-					expandedPropertyValue, containsExpandedProperty := reverseMap[expandedProperty]
-					if containsExpandedProperty {
-						expandedPropertyList = expandedPropertyValue.([]interface{})
-					} else {
-						expandedPropertyList = make([]interface{}, 0)
-					}
-
-					switch v := item.(type) {
-					case map[string]interface{}:
-						// 7.10.4.1)
-						_, containsValue := v["@value"]
-						_, containsList := v["@list"]
-						if containsValue || containsList {
-							return nil, NewJsonLdError(InvalidReversePropertyValue, nil)
-						}
-						expandedPropertyList = append(expandedPropertyList, v)
-					case []interface{}:
-						// 7.10.4.3)
-						expandedPropertyList = append(expandedPropertyList, v...)
-					default:
-						expandedPropertyList = append(expandedPropertyList, v)
+			}
+			sort.Strings(types)
+			for _, t := range types {
+				if typeScopedContext, hasContext := typeScopedCtx.GetTermDefinition(t)["@context"]; hasContext {
+					newCtx, err := activeCtx.parse(typeScopedContext, make([]string, 0), false, false, true)
+					if err != nil {
+						return nil, err
 					}
-					reverseMap[expandedProperty] = expandedPropertyList
-				}
-			} else { // 7.11)
-				// 7.11.1)
-				var expandedPropertyList []interface{}
-				expandedPropertyValue, containsExpandedProperty := resultMap[expandedProperty]
-				if containsExpandedProperty {
-					expandedPropertyList = expandedPropertyValue.([]interface{})
-				} else {
-					expandedPropertyList = make([]interface{}, 0)
-					resultMap[expandedProperty] = expandedPropertyList
-				}
-				// 7.11.2)
-				if expandedValueList, isList := expandedValue.([]interface{}); isList {
-					expandedPropertyList = append(expandedPropertyList, expandedValueList...)
-				} else {
-					expandedPropertyList = append(expandedPropertyList, expandedValue)
+					activeCtx = newCtx
 				}
-				resultMap[expandedProperty] = expandedPropertyList
 			}
 		}
+		// 6)
+		resultMap := make(map[string]interface{})
+		if err := api.expandObject(activeCtx, typeScopedCtx, activeProperty, inputType, elem, resultMap, opts); err != nil {
+			return nil, err
+		}
 		// 8)
 		if rval, hasValue := resultMap["@value"]; hasValue {
 			// 8.1)
 			allowedKeys := map[string]interface{}{
-				"@value":    nil,
-				"@index":    nil,
-				"@language": nil,
-				"@type":     nil,
+				"@value":     nil,
+				"@index":     nil,
+				"@language":  nil,
+				"@type":      nil,
+				"@direction": nil,
 			}
 			hasDisallowedKeys := false
 			for key := range resultMap {
@@ -472,21 +171,25 @@ func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element
 				}
 			}
 			_, hasLanguage := resultMap["@language"]
+			_, hasDirection := resultMap["@direction"]
 			typeValue, hasType := resultMap["@type"]
-			if hasDisallowedKeys || (hasLanguage && hasType) {
+			if hasDisallowedKeys || ((hasLanguage || hasDirection) && hasType) {
 				return nil, NewJsonLdError(InvalidValueObject, "value object has unknown keys")
 			}
+			// JSON-LD 1.1: the value of a JSON literal may be any JSON value
+			isJSONLiteral := hasType && typeValue == "@json"
 			// 8.2)
-			if rval == nil {
+			if rval == nil && !isJSONLiteral {
 				// nothing else is possible with result if we set it to
 				// null, so simply return it
 				return nil, nil
 			}
 			// 8.3)
-			if _, isString := rval.(string); !isString && hasLanguage {
+			// JSON-LD 1.1: value patterns in frames aren't validated
+			if _, isString := rval.(string); !isString && hasLanguage && !frameExpansion {
 				return nil, NewJsonLdError(InvalidLanguageTaggedValue,
 					"when @language is used, @value must be a string")
-			} else if hasType { // 8.4)
+			} else if hasType && !isJSONLiteral && !frameExpansion { // 8.4)
 				// TODO: is this enough for "is an IRI"
 				typeStr, isString := typeValue.(string)
 				if !isString || strings.HasPrefix(typeStr, "_:") || !strings.Contains(typeStr, ":") {
@@ -534,7 +237,8 @@ func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element
 			_, hasValue := resultMap["@value"]
 			_, hasList := resultMap["@list"]
 			_, hasID := resultMap["@id"]
-			if resultMap != nil && (len(resultMap) == 0 || hasValue || hasList) {
+			// JSON-LD 1.1: empty frames are kept in frame expansion
+			if resultMap != nil && ((len(resultMap) == 0 && !frameExpansion) || hasValue || hasList) {
 				resultMap = nil
 				result = nil
 			} else if resultMap != nil && !frameExpansion && hasID && len(resultMap) == 1 { // 12.2)
@@ -550,6 +254,632 @@ func (api *JsonLdApi) Expand(activeCtx *Context, activeProperty string, element
 		if activeProperty == "" || activeProperty == "@graph" {
 			return nil, nil
 		}
+		// JSON-LD 1.1: apply the property-scoped context
+		if hasPropertyScopedContext {
+			newCtx, err := activeCtx.parse(propertyScopedContext, make([]string, 0), true, true, true)
+			if err != nil {
+				return nil, err
+			}
+			activeCtx = newCtx
+		}
 		return activeCtx.ExpandValue(activeProperty, element)
 	}
 }
+
+// expandObject expands the properties of the node object element and adds them to resultMap.
+// It implements steps 7 of the Expansion algorithm and, for JSON-LD 1.1, expands the values
+// of @nest properties into the same result.
+func (api *JsonLdApi) expandObject(activeCtx *Context, typeScopedCtx *Context, activeProperty string, inputType string,
+	elem map[string]interface{}, resultMap map[string]interface{}, opts *JsonLdOptions) error {
+	frameExpansion := opts.ProcessingMode == JsonLd_1_1_Frame
+	nests := make([]string, 0)
+
+	// 7)
+	for _, key := range GetOrderedKeys(elem) {
+		value := elem[key]
+		// 7.1)
+		if key == "@context" {
+			continue
+		}
+		// 7.2)
+		expandedProperty, err := activeCtx.ExpandIri(key, false, true, nil, nil)
+		if err != nil {
+			return err
+		}
+		var expandedValue interface{}
+		// 7.3)
+		if expandedProperty == "" || (!strings.Contains(expandedProperty, ":") && !IsKeyword(expandedProperty)) {
+			continue
+		}
+		// 7.4)
+		if IsKeyword(expandedProperty) {
+			// 7.4.1)
+			if activeProperty == "@reverse" {
+				return NewJsonLdError(InvalidReversePropertyMap,
+					"a keyword cannot be used as a @reverse propery")
+			}
+			// JSON-LD 1.1: values of @nest are expanded after all other properties
+			if expandedProperty == "@nest" {
+				nests = append(nests, key)
+				continue
+			}
+			// 7.4.2)
+			// JSON-LD 1.1: @included may come from several nested objects
+			if _, containsKey := resultMap[expandedProperty]; containsKey && expandedProperty != "@included" {
+				return NewJsonLdError(CollidingKeywords, expandedProperty+" already exists in result")
+			}
+			// 7.4.3)
+			if expandedProperty == "@id" {
+				valueStr, isString := value.(string)
+				if isString {
+					expandedValue, err = activeCtx.ExpandIri(valueStr, true, false, nil, nil)
+					if err != nil {
+						return err
+					}
+				} else if frameExpansion {
+					if valueMap, isMap := value.(map[string]interface{}); isMap {
+						if len(valueMap) != 0 {
+							return NewJsonLdError(InvalidIDValue, "@id value must be a an empty object for framing")
+						}
+						expandedValue = value
+					} else if valueList, isList := value.([]interface{}); isList {
+						expandedValueList := make([]interface{}, 0)
+						for _, v := range valueList {
+							vString, isString := v.(string)
+							if !isString {
+								return NewJsonLdError(InvalidIDValue, "@id value must be a string, an array of strings or an empty dictionary")
+							}
+							v, err := activeCtx.ExpandIri(vString, true, false, nil, nil)
+							if err != nil {
+								return err
+							}
+							expandedValueList = append(expandedValueList, v)
+						}
+						expandedValue = expandedValueList
+					} else {
+						return NewJsonLdError(InvalidIDValue, "value of @id must be a string, an array of strings or an empty dictionary")
+					}
+				} else {
+					return NewJsonLdError(InvalidIDValue, "value of @id must be a string")
+				}
+			} else if expandedProperty == "@type" { // 7.4.4)
+				switch v := value.(type) {
+				case []interface{}:
+					var expandedValueList []interface{}
+					for _, listElem := range v {
+						listElemStr, isString := listElem.(string)
+						if !isString {
+							return NewJsonLdError(InvalidTypeValue,
+								"@type value must be a string or array of strings")
+						}
+						newVal, err := typeScopedCtx.ExpandIri(listElemStr, true, true, nil, nil)
+						if err != nil {
+							return err
+						}
+						expandedValueList = append(expandedValueList, newVal)
+					}
+					expandedValue = expandedValueList
+				case string:
+					expandedValue, err = typeScopedCtx.ExpandIri(v, true, true, nil, nil)
+					if err != nil {
+						return err
+					}
+				case map[string]interface{}:
+					if len(v) != 0 {
+						return NewJsonLdError(InvalidTypeValue,
+							"@type value must be a an empty object for framing")
+					}
+					expandedValue = value
+				default:
+					return NewJsonLdError(InvalidTypeValue, "@type value must be a string or array of strings")
+				}
+			} else if expandedProperty == "@graph" { // 7.4.5)
+				expandedValue, _ = api.expand(activeCtx, "@graph", value, opts, false)
+			} else if expandedProperty == "@value" { // 7.4.6)
+				// JSON-LD 1.1: the value of a JSON literal may be any JSON value
+				if inputType == "@json" {
+					if !activeCtx.processingMode(1.1) {
+						return NewJsonLdError(InvalidValueObjectValue, "JSON literals are not allowed in JSON-LD 1.0")
+					}
+					resultMap["@value"] = value
+					continue
+				}
+				valueMap, isMap := value.(map[string]interface{})
+				valueList, isList := value.([]interface{})
+				if frameExpansion && ((isMap && len(valueMap) == 0) || (isList && isScalarList(valueList))) {
+					// JSON-LD 1.1: value patterns in frames may use a wildcard or a list of values
+					resultMap["@value"] = value
+					continue
+				}
+				if value != nil && (isMap || isList) {
+					return NewJsonLdError(InvalidValueObjectValue, "value of "+
+						expandedProperty+" must be a scalar or null")
+				}
+				expandedValue = value
+				if expandedValue == nil {
+					resultMap["@value"] = nil
+					continue
+				}
+			} else if expandedProperty == "@language" { // 7.4.7)
+				valueStr, isString := value.(string)
+				valueMap, isMap := value.(map[string]interface{})
+				valueList, isList := value.([]interface{})
+				if frameExpansion && isMap && len(valueMap) == 0 {
+					// JSON-LD 1.1: language wildcard in a value pattern
+					expandedValue = value
+				} else if frameExpansion && isList {
+					// JSON-LD 1.1: list of languages in a value pattern
+					languages := make([]interface{}, 0, len(valueList))
+					for _, v := range valueList {
+						language, isString := v.(string)
+						if !isString {
+							return NewJsonLdError(InvalidLanguageTaggedString, "Value of "+
+								expandedProperty+" must be a string, an array of strings or an empty dictionary")
+						}
+						languages = append(languages, strings.ToLower(language))
+					}
+					expandedValue = languages
+				} else if !isString {
+					return NewJsonLdError(InvalidLanguageTaggedString, "Value of "+
+						expandedProperty+" must be a string")
+				} else {
+					expandedValue = strings.ToLower(valueStr)
+				}
+			} else if expandedProperty == "@direction" { // JSON-LD 1.1
+				if !activeCtx.processingMode(1.1) {
+					continue
+				}
+				if !isValidDirection(value) {
+					return NewJsonLdError(InvalidBaseDirection, "@direction must be either ltr or rtl")
+				}
+				expandedValue = value
+			} else if expandedProperty == "@index" { // 7.4.8)
+				_, isString := value.(string)
+				if !isString {
+					return NewJsonLdError(InvalidIndexValue, "Value of "+
+						expandedProperty+" must be a string")
+				}
+				expandedValue = value
+			} else if expandedProperty == "@list" { // 7.4.9)
+				// 7.4.9.1)
+				if activeProperty == "" || activeProperty == "@graph" {
+					continue
+				}
+				// 7.4.9.2)
+				expandedValue, _ = api.expand(activeCtx, activeProperty, value, opts, false)
+
+				// NOTE: step not in the spec yet
+				expandedValueList, isList := expandedValue.([]interface{})
+				if !isList {
+					expandedValueList = []interface{}{expandedValue}
+					expandedValue = expandedValueList
+				}
+
+				// 7.4.9.3)
+				for _, o := range expandedValueList {
+					oMap, isMap := o.(map[string]interface{})
+					if _, containsList := oMap["@list"]; isMap && containsList {
+						return NewJsonLdError(ListOfLists, "A list may not contain another list")
+					}
+				}
+			} else if expandedProperty == "@set" { // 7.4.10)
+				expandedValue, _ = api.expand(activeCtx, activeProperty, value, opts, false)
+			} else if expandedProperty == "@reverse" { // 7.4.11)
+				_, isMap := value.(map[string]interface{})
+				if !isMap {
+					return NewJsonLdError(InvalidReverseValue, "@reverse value must be an object")
+				}
+				// 7.4.11.1)
+				expandedValue, err = api.expand(activeCtx, "@reverse", value, opts, false)
+				if err != nil {
+					return err
+				}
+
+				// NOTE: algorithm assumes the result is a map
+				// 7.4.11.2)
+				reverseValue, containsReverse := expandedValue.(map[string]interface{})["@reverse"]
+				if containsReverse {
+					for property, item := range reverseValue.(map[string]interface{}) {
+						// 7.4.11.2.1)
+						var propertyList []interface{}
+						if propertyValue, containsProperty := resultMap[property]; containsProperty {
+							propertyList = propertyValue.([]interface{})
+						} else {
+							propertyList = make([]interface{}, 0)
+							resultMap[property] = propertyList
+						}
+						// 7.4.11.2.2)
+						if itemList, isList := item.([]interface{}); isList {
+							propertyList = append(propertyList, itemList...)
+						} else {
+							propertyList = append(propertyList, item)
+						}
+						resultMap[property] = propertyList
+					}
+				}
+				// 7.4.11.3)
+				expandedValueMap := expandedValue.(map[string]interface{})
+				var maxSize int
+				if containsReverse {
+					maxSize = 1
+				} else {
+					maxSize = 0
+				}
+				if len(expandedValueMap) > maxSize {
+					var reverseMap map[string]interface{}
+					if reverseValue, containsReverse := resultMap["@reverse"]; containsReverse {
+						// 7.4.11.3.2)
+						reverseMap = reverseValue.(map[string]interface{})
+					} else {
+						// 7.4.11.3.1)
+						reverseMap = make(map[string]interface{})
+						resultMap["@reverse"] = reverseMap
+					}
+
+					// 7.4.11.3.3)
+					for property, propertyValue := range expandedValueMap {
+						if property == "@reverse" {
+							continue
+						}
+						// 7.4.11.3.3.1)
+						items := propertyValue.([]interface{})
+						for _, item := range items {
+							// 7.4.11.3.3.1.1)
+							itemMap := item.(map[string]interface{})
+							_, containsValue := itemMap["@value"]
+							_, containsList := itemMap["@list"]
+							if containsValue || containsList {
+								return NewJsonLdError(InvalidReversePropertyValue, nil)
+							}
+							// 7.4.11.3.3.1.2)
+							var propertyValueList []interface{}
+							propertyValue, containsProperty := reverseMap[property]
+							if containsProperty {
+								propertyValueList = propertyValue.([]interface{})
+							} else {
+								propertyValueList = make([]interface{}, 0)
+								reverseMap[property] = propertyValueList
+							}
+							// 7.4.11.3.3.1.3)
+							reverseMap[property] = append(propertyValueList, item)
+						}
+					}
+				}
+				// 7.4.11.4)
+				continue
+			} else if expandedProperty == "@included" { // JSON-LD 1.1
+				if !activeCtx.processingMode(1.1) {
+					continue
+				}
+				includedValue, err := api.expand(activeCtx, activeProperty, value, opts, false)
+				if err != nil {
+					return err
+				}
+				includedList, isList := includedValue.([]interface{})
+				if !isList {
+					includedList = []interface{}{includedValue}
+				}
+				for _, item := range includedList {
+					itemMap, isMap := item.(map[string]interface{})
+					_, containsValue := itemMap["@value"]
+					_, containsList := itemMap["@list"]
+					_, containsSet := itemMap["@set"]
+					if !isMap || containsValue || containsList || containsSet {
+						return NewJsonLdError(InvalidIncludedValue, "values of @included must be node objects")
+					}
+				}
+				if previousValue, containsIncluded := resultMap["@included"]; containsIncluded {
+					includedList = append(previousValue.([]interface{}), includedList...)
+				}
+				expandedValue = includedList
+			} else if expandedProperty == "@explicit" || // TODO: SPEC no mention of @explicit etc in spec
+				expandedProperty == "@default" ||
+				expandedProperty == "@embed" ||
+				expandedProperty == "@embedChildren" ||
+				expandedProperty == "@omitDefault" ||
+				expandedProperty == "@requireAll" {
+				expandedValue, _ = api.expand(activeCtx, expandedProperty, value, opts, false)
+			}
+			// 7.4.12)
+			if expandedValue != nil {
+				resultMap[expandedProperty] = expandedValue
+			}
+			// 7.4.13)
+			continue
+		} else {
+			valueMap, isMap := value.(map[string]interface{})
+			// JSON-LD 1.1: values of terms with @json type mapping are JSON literals
+			if activeCtx.GetTypeMapping(key) == "@json" {
+				expandedValue = map[string]interface{}{
+					"@value": value,
+					"@type":  "@json",
+				}
+			} else if activeCtx.HasContainerMapping(key, "@language") && isMap { // 7.5
+				// 7.5.1)
+				var expandedValueList []interface{}
+				// JSON-LD 1.1: base direction of the strings in the language map
+				direction := activeCtx.GetDirection(key)
+				// 7.5.2)
+				for _, language := range GetOrderedKeys(valueMap) {
+					languageValue := valueMap[language]
+					// 7.5.2.1)
+					languageList, isList := languageValue.([]interface{})
+					if !isList {
+						languageList = []interface{}{languageValue}
+					}
+					// JSON-LD 1.1: values under @none have no language
+					expandedLanguage, err := activeCtx.ExpandIri(language, false, true, nil, nil)
+					if err != nil {
+						return err
+					}
+					// 7.5.2.2)
+					for _, item := range languageList {
+						// JSON-LD 1.1: null values are ignored
+						if item == nil {
+							continue
+						}
+						// 7.5.2.2.1)
+						if _, isString := item.(string); !isString {
+							return NewJsonLdError(InvalidLanguageMapValue, "Expected "+
+								fmt.Sprintf("%v", item)+" to be a string")
+						}
+						// 7.5.2.2.2)
+						v := map[string]interface{}{
+							"@value": item,
+						}
+						if expandedLanguage != "@none" {
+							v["@language"] = strings.ToLower(language)
+						}
+						if direction != "" {
+							v["@direction"] = direction
+						}
+						expandedValueList = append(expandedValueList, v)
+					}
+				}
+				expandedValue = expandedValueList
+			} else if (activeCtx.HasContainerMapping(key, "@index") || activeCtx.HasContainerMapping(key, "@id") ||
+				activeCtx.HasContainerMapping(key, "@type")) && isMap { // 7.6)
+				// JSON-LD 1.1: the keys of id and type maps are the @id and @type values of the items
+				isIDMap := activeCtx.HasContainerMapping(key, "@id")
+				isTypeMap := activeCtx.HasContainerMapping(key, "@type")
+				// JSON-LD 1.1: the keys of an index map may be values of a property
+				indexKey, isPropertyIndex := activeCtx.GetTermDefinition(key)["@index"].(string)
+				// 7.6.1)
+				var expandedValueList []interface{}
+				// 7.6.2)
+				for _, index := range GetOrderedKeys(valueMap) {
+					indexValue := valueMap[index]
+					// JSON-LD 1.1: values of id and type maps are not in scope of a type-scoped context,
+					// but the values of a type map are in scope of the context of their type
+					mapCtx := activeCtx
+					if (isIDMap || isTypeMap) && activeCtx.previousContext != nil {
+						mapCtx = activeCtx.previousContext
+					}
+					if typeScopedContext, hasContext := mapCtx.GetTermDefinition(index)["@context"]; isTypeMap &&
+						hasContext {
+						mapCtx, err = mapCtx.parse(typeScopedContext, make([]string, 0), false, false, true)
+						if err != nil {
+							return err
+						}
+					}
+					// JSON-LD 1.1: values under @none have no index. The keys of a type map are
+					// types, which may be relative to the document
+					expandedIndex, err := activeCtx.ExpandIri(index, isTypeMap, true, nil, nil)
+					if err != nil {
+						return err
+					}
+					// 7.6.2.1)
+					indexValueList, isList := indexValue.([]interface{})
+					if !isList {
+						indexValueList = []interface{}{indexValue}
+					}
+					// 7.6.2.2)
+					indexValue, err = api.expand(mapCtx, key, indexValueList, opts, true)
+					if err != nil {
+						return err
+					}
+					// 7.6.2.3)
+					for _, itemValue := range indexValue.([]interface{}) {
+						// JSON-LD 1.1: items of a graph map are graph objects
+						if activeCtx.HasContainerMapping(key, "@graph") && !IsGraph(itemValue) {
+							itemValue = map[string]interface{}{"@graph": []interface{}{itemValue}}
+						}
+						item := itemValue.(map[string]interface{})
+						_, containsIndex := item["@index"]
+						_, containsID := item["@id"]
+						if expandedIndex == "@none" {
+							// the item is added as is
+						} else if isPropertyIndex {
+							if _, containsValue := item["@value"]; containsValue {
+								return NewJsonLdError(InvalidValueObject,
+									"values of a property-valued index map must be node objects")
+							}
+							reExpandedIndex, err := activeCtx.ExpandValue(indexKey, index)
+							if err != nil {
+								return err
+							}
+							expandedIndexKey, err := activeCtx.ExpandIri(indexKey, false, true, nil, nil)
+							if err != nil {
+								return err
+							}
+							indexPropertyValues := []interface{}{reExpandedIndex}
+							if existingValues, isList := item[expandedIndexKey].([]interface{}); isList {
+								indexPropertyValues = append(indexPropertyValues, existingValues...)
+							} else if existingValue, containsKey := item[expandedIndexKey]; containsKey {
+								indexPropertyValues = append(indexPropertyValues, existingValue)
+							}
+							item[expandedIndexKey] = indexPropertyValues
+						} else if !isIDMap && !isTypeMap && !containsIndex { // 7.6.2.3.1)
+							item["@index"] = index
+						} else if isIDMap && !containsID {
+							item["@id"], err = activeCtx.ExpandIri(index, true, false, nil, nil)
+							if err != nil {
+								return err
+							}
+						} else if isTypeMap {
+							types := []interface{}{expandedIndex}
+							if typeList, isList := item["@type"].([]interface{}); isList {
+								types = append(types, typeList...)
+							} else if typeVal, containsType := item["@type"]; containsType {
+								types = append(types, typeVal)
+							}
+							item["@type"] = types
+						}
+						// 7.6.2.3.2)
+						expandedValueList = append(expandedValueList, item)
+					}
+				}
+				expandedValue = expandedValueList
+			} else {
+				// 7.7)
+				expandedValue, err = api.expand(activeCtx, key, value, opts, false)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// 7.8)
+		if expandedValue == nil {
+			continue
+		}
+		// 7.9)
+		if activeCtx.HasContainerMapping(key, "@list") {
+			expandedValueMap, isMap := expandedValue.(map[string]interface{})
+			_, containsList := expandedValueMap["@list"]
+			if !isMap || !containsList {
+				newExpandedValue := make(map[string]interface{}, 1)
+				_, isList := expandedValue.([]interface{})
+				if !isList {
+					newExpandedValue["@list"] = []interface{}{expandedValue}
+				} else {
+					newExpandedValue["@list"] = expandedValue
+				}
+				expandedValue = newExpandedValue
+			}
+		}
+		// JSON-LD 1.1: values of a term with a @graph container are wrapped in graph objects
+		if activeCtx.HasContainerMapping(key, "@graph") && !activeCtx.HasContainerMapping(key, "@id") &&
+			!activeCtx.HasContainerMapping(key, "@index") {
+			expandedValueList, isList := expandedValue.([]interface{})
+			if !isList {
+				expandedValueList = []interface{}{expandedValue}
+			}
+			graphObjects := make([]interface{}, 0, len(expandedValueList))
+			for _, ev := range expandedValueList {
+				evList, isList := ev.([]interface{})
+				if !isList {
+					evList = []interface{}{ev}
+				}
+				graphObjects = append(graphObjects, map[string]interface{}{"@graph": evList})
+			}
+			expandedValue = graphObjects
+		}
+		// 7.10)
+		if activeCtx.IsReverseProperty(key) {
+			var reverseMap map[string]interface{}
+			if reverseValue, containsReverse := resultMap["@reverse"]; containsReverse {
+				// 7.10.2)
+				reverseMap = reverseValue.(map[string]interface{})
+			} else {
+				// 7.10.1)
+				reverseMap = make(map[string]interface{})
+				resultMap["@reverse"] = reverseMap
+			}
+
+			// 7.10.3)
+			expandedValueList, isList := expandedValue.([]interface{})
+			if !isList {
+				expandedValueList = []interface{}{expandedValue}
+				expandedValue = expandedValueList
+			}
+			// 7.10.4)
+			for _, item := range expandedValueList {
+
+				// 7.10.4.2)
+				var expandedPropertyList []interface{}
+				expandedPropertyValue, containsExpandedProperty := reverseMap[expandedProperty]
+				if containsExpandedProperty {
+					expandedPropertyList = expandedPropertyValue.([]interface{})
+				} else {
+					expandedPropertyList = make([]interface{}, 0)
+				}
+
+				switch v := item.(type) {
+				case map[string]interface{}:
+					// 7.10.4.1)
+					_, containsValue := v["@value"]
+					_, containsList := v["@list"]
+					if containsValue || containsList {
+						return NewJsonLdError(InvalidReversePropertyValue, nil)
+					}
+					expandedPropertyList = append(expandedPropertyList, v)
+				case []interface{}:
+					// 7.10.4.3)
+					expandedPropertyList = append(expandedPropertyList, v...)
+				default:
+					expandedPropertyList = append(expandedPropertyList, v)
+				}
+				reverseMap[expandedProperty] = expandedPropertyList
+			}
+		} else { // 7.11)
+			// 7.11.1)
+			var expandedPropertyList []interface{}
+			expandedPropertyValue, containsExpandedProperty := resultMap[expandedProperty]
+			if containsExpandedProperty {
+				expandedPropertyList = expandedPropertyValue.([]interface{})
+			} else {
+				expandedPropertyList = make([]interface{}, 0)
+				resultMap[expandedProperty] = expandedPropertyList
+			}
+			// 7.11.2)
+			if expandedValueList, isList := expandedValue.([]interface{}); isList {
+				expandedPropertyList = append(expandedPropertyList, expandedValueList...)
+			} else {
+				expandedPropertyList = append(expandedPropertyList, expandedValue)
+			}
+			resultMap[expandedProperty] = expandedPropertyList
+		}
+	}
+
+	// JSON-LD 1.1: expand nested values as if they were values of element
+	sort.Strings(nests)
+	for _, nestingKey := range nests {
+		nestedValues, isList := elem[nestingKey].([]interface{})
+		if !isList {
+			nestedValues = []interface{}{elem[nestingKey]}
+		}
+		for _, nestedValue := range nestedValues {
+			nestedMap, isMap := nestedValue.(map[string]interface{})
+			if !isMap {
+				return NewJsonLdError(InvalidNestValue, "nested value must be a node object")
+			}
+			for key := range nestedMap {
+				expandedKey, err := activeCtx.ExpandIri(key, false, true, nil, nil)
+				if err != nil {
+					return err
+				}
+				if expandedKey == "@value" {
+					return NewJsonLdError(InvalidNestValue, "nested value must be a node object")
+				}
+			}
+			if err := api.expandObject(activeCtx, typeScopedCtx, activeProperty, inputType, nestedMap, resultMap,
+				opts); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isScalarList returns true if all items of the given list are scalars.
+func isScalarList(list []interface{}) bool {
+	for _, item := range list {
+		switch item.(type) {
+		case map[string]interface{}, []interface{}, nil:
+			return false
+		}
+	}
+	return true
+}
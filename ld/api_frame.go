@@ -1,13 +1,19 @@
 package ld
 
+import (
+	"strings"
+)
+
 // Embed is an enum representing allowed Embed flag options as per Framing spec
-type Embed int
+type Embed string
 
 const (
-	Always Embed = 1 + iota
-	Never
-	Last
-	Link
+	Always Embed = "@always"
+	Once   Embed = "@once"
+	Never  Embed = "@never"
+	Link   Embed = "@link"
+	// Last is the JSON-LD 1.0 framing behaviour: only the last match is embedded
+	Last Embed = "@last"
 )
 
 // EmbedNode represents embed meta info
@@ -16,13 +22,23 @@ type EmbedNode struct {
 	property string
 }
 
+// StackNode is an entry of the subject stack, which is used to detect circular references
+type StackNode struct {
+	subject string
+	graph   string
+}
+
 // FramingContext stores framing state
 type FramingContext struct {
 	embed        Embed
 	explicit     bool
 	omitDefault  bool
-	uniqueEmbeds map[string]*EmbedNode
-	subjectStack []string
+	requireAll   bool
+	graph        string
+	graphMap     map[string]interface{}
+	uniqueEmbeds map[string]map[string]*EmbedNode
+	link         map[string]map[string]interface{}
+	subjectStack []*StackNode
 }
 
 // NewFramingContext creates and returns as new framing context.
@@ -31,19 +47,25 @@ func NewFramingContext(opts *JsonLdOptions) *FramingContext {
 		embed:        Last,
 		explicit:     false,
 		omitDefault:  false,
-		uniqueEmbeds: make(map[string]*EmbedNode),
-		subjectStack: make([]string, 0),
+		requireAll:   false,
+		graph:        "@default",
+		graphMap:     make(map[string]interface{}),
+		uniqueEmbeds: make(map[string]map[string]*EmbedNode),
+		link:         make(map[string]map[string]interface{}),
+		subjectStack: make([]*StackNode, 0),
 	}
 
 	if opts != nil {
-		// TODO: make embed field a selector instead of a boolean, as per new spec.
-		embedVal := Never
-		if opts.Embed {
-			embedVal = Last
+		// JSON-LD 1.1: the default @embed flag is @once,
+		// JSON-LD 1.0 framing embeds the last match
+		if opts.Embed != "" {
+			context.embed = opts.Embed
+		} else if isProcessingMode(opts.ProcessingMode, 1.1) {
+			context.embed = Once
 		}
-		context.embed = embedVal
 		context.explicit = opts.Explicit
 		context.omitDefault = opts.OmitDefault
+		context.requireAll = opts.RequireAll
 	}
 
 	return context
@@ -63,9 +85,15 @@ func (api *JsonLdApi) Frame(input interface{}, frame []interface{}, opts *JsonLd
 	// create framing state
 	state := NewFramingContext(opts)
 
-	nodes := make(map[string]interface{})
-	api.GenerateNodeMap(input, nodes, "@default", nil, "", nil, issuer)
-	nodeMap := nodes["@default"].(map[string]interface{})
+	state.graphMap["@default"] = make(map[string]interface{})
+	api.GenerateNodeMap(input, state.graphMap, "@default", nil, "", nil, issuer)
+
+	// JSON-LD 1.1: unless only the default graph is framed, frame the merged node objects of all graphs
+	if opts == nil || !opts.FrameDefault {
+		state.graphMap["@merged"] = mergeNodeMaps(state.graphMap)
+		state.graph = "@merged"
+	}
+	nodeMap := state.graphMap[state.graph].(map[string]interface{})
 
 	framed := make([]interface{}, 0)
 
@@ -86,9 +114,39 @@ func (api *JsonLdApi) Frame(input interface{}, frame []interface{}, opts *JsonLd
 	return framedVal.([]interface{}), nil
 }
 
-func createsCircularReference(id string, state *FramingContext) bool {
-	for _, i := range state.subjectStack {
-		if i == id {
+// mergeNodeMaps merges the node objects of all graphs in the given graph map
+// into a single node map.
+func mergeNodeMaps(graphMap map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, graphName := range GetOrderedKeys(graphMap) {
+		nodeMap := graphMap[graphName].(map[string]interface{})
+		for _, id := range GetOrderedKeys(nodeMap) {
+			node := nodeMap[id].(map[string]interface{})
+			mergedNode, hasNode := result[id].(map[string]interface{})
+			if !hasNode {
+				mergedNode = map[string]interface{}{"@id": id}
+				result[id] = mergedNode
+			}
+			for _, property := range GetOrderedKeys(node) {
+				if IsKeyword(property) && property != "@type" {
+					mergedNode[property] = CloneDocument(node[property])
+				} else {
+					for _, value := range node[property].([]interface{}) {
+						MergeValue(mergedNode, property, CloneDocument(value))
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// createsCircularReference returns true if embedding the node with the given id
+// in the given graph would create a circular reference, i.e. if the node is already
+// being framed in the same graph.
+func createsCircularReference(id string, graph string, state *FramingContext) bool {
+	for i := len(state.subjectStack) - 1; i >= 0; i-- {
+		if state.subjectStack[i].graph == graph && state.subjectStack[i].subject == id {
 			return true
 		}
 	}
@@ -110,32 +168,47 @@ func (api *JsonLdApi) frame(state *FramingContext, nodes map[string]interface{},
 	// Initialize flags embed, explicit, and requireAll from object embed flag,
 	// explicit inclusion flag, and require all flag in state overriding from
 	// any property values for @embed, @explicit, and @requireAll in frame.
-	// TODO: handle @requireAll
 	embed, err := getFrameEmbed(frame, state.embed)
 	if err != nil {
 		return nil, err
 	}
 	explicitOn := GetFrameFlag(frame, "@explicit", state.explicit)
+	requireAll := GetFrameFlag(frame, "@requireAll", state.requireAll)
 	flags := make(map[string]interface{})
 	flags["@explicit"] = explicitOn
 	flags["@embed"] = embed
+	flags["@requireAll"] = requireAll
 
 	// 3.
 	// Create a list of matched subjects by filtering subjects against frame
 	// using the Frame Matching algorithm with state, subjects, frame, and requireAll.
-	matches, err := FilterNodes(nodes, frame)
+	matches, err := FilterNodes(nodes, frame, nodeMap, requireAll)
 	if err != nil {
 		return nil, err
 	}
 
 	// 4.
 	// Set link the the value of link in state associated with graph name in state,
-	// creating a new empty dictionary, if necessary. TODO
-	//link := state.uniqueEmbeds;
+	// creating a new empty dictionary, if necessary.
+	link, hasLink := state.link[state.graph]
+	if !hasLink {
+		link = make(map[string]interface{})
+		state.link[state.graph] = link
+	}
 
 	// 5.
 	// For each id and associated node object node from the set of matched subjects, ordered by id:
 	for _, id := range GetOrderedKeys(matches) {
+		// Occurs only at top level, compartmentalize each top-level match
+		if property == "" {
+			state.uniqueEmbeds = make(map[string]map[string]*EmbedNode)
+		}
+		uniqueEmbeds, hasEmbeds := state.uniqueEmbeds[state.graph]
+		if !hasEmbeds {
+			uniqueEmbeds = make(map[string]*EmbedNode)
+			state.uniqueEmbeds[state.graph] = uniqueEmbeds
+		}
+
 		// 5.1
 		// Initialize output to a new dictionary with @id and id and add output to link associated with id.
 		output := make(map[string]interface{})
@@ -146,41 +219,77 @@ func (api *JsonLdApi) frame(state *FramingContext, nodes map[string]interface{},
 		// Add the associated node object from link to parent and do not perform
 		// additional processing for this node.
 		if embed == Link {
-			if idVal, containsId := state.uniqueEmbeds[id]; containsId {
-				parent = addFrameOutput(parent, property, idVal)
+			if linkedNode, containsID := link[id]; containsID {
+				parent = addFrameOutput(parent, property, linkedNode)
 				continue
 			}
 		}
-
-		// Occurs only at top level, compartmentalize each top-level match
-		if property == "" {
-			state.uniqueEmbeds = make(map[string]*EmbedNode)
-		}
+		link[id] = output
 
 		// 5.3
 		// Otherwise, if embed is @never or if a circular reference would be created by an embed,
 		// add output to parent and do not perform additional processing for this node.
-		if embed == Never || createsCircularReference(id, state) {
+		if embed == Never || createsCircularReference(id, state.graph, state) {
 			parent = addFrameOutput(parent, property, output)
 			continue
 		}
 
 		// 5.4
-		// Otherwise, if embed is @last, remove any existing embedded node from parent associated
-		// with graph name in state. Requires sorting of subjects.
-		if embed == Last {
-			if _, containsId := state.uniqueEmbeds[id]; containsId {
-				removeEmbed(state, id)
+		// Otherwise, if embed is @once and parent has an existing embedded node in parent
+		// associated with graph name and id in state, add output to parent and do not
+		// perform additional processing for this node.
+		if _, containsID := uniqueEmbeds[id]; embed == Once && containsID {
+			parent = addFrameOutput(parent, property, output)
+			continue
+		}
+
+		// JSON-LD 1.0: if embed is @last, remove any existing embedded node from parent.
+		// Requires sorting of subjects.
+		if _, containsID := uniqueEmbeds[id]; embed == Last && containsID {
+			removeEmbed(state, id)
+		}
+		uniqueEmbeds[id] = &EmbedNode{
+			parent:   parent,
+			property: property,
+		}
+
+		state.subjectStack = append(state.subjectStack, &StackNode{
+			subject: id,
+			graph:   state.graph,
+		})
+
+		// JSON-LD 1.1: if the node is also the name of a graph, frame the nodes of that graph
+		if graphNodes, isGraphName := state.graphMap[id].(map[string]interface{}); isGraphName {
+			recurse := false
+			subframe := make(map[string]interface{})
+			if graphFrame, hasGraph := frame["@graph"]; hasGraph {
+				// @graph in frame selects the nodes of the named graph
+				recurse = id != "@merged" && id != "@default"
+				if graphFrameList, isList := graphFrame.([]interface{}); isList && len(graphFrameList) > 0 {
+					graphFrame = graphFrameList[0]
+				}
+				if graphFrameMap, isMap := graphFrame.(map[string]interface{}); isMap {
+					subframe = graphFrameMap
+				}
+			} else {
+				// named graphs are embedded unless framing the merged graph
+				recurse = state.graph != "@merged"
 			}
-			state.uniqueEmbeds[id] = &EmbedNode{
-				parent:   parent,
-				property: property,
+
+			if recurse {
+				savedGraph := state.graph
+				savedStack := state.subjectStack
+				state.graph = id
+				state.subjectStack = make([]*StackNode, 0)
+				if _, err := api.frame(state, graphNodes, graphNodes, subframe, output, "@graph"); err != nil {
+					return nil, err
+				}
+				state.graph = savedGraph
+				state.subjectStack = savedStack
 			}
 		}
 
-		state.subjectStack = append(state.subjectStack, id)
-
-		// 5.5 If embed is @last or @always
+		// 5.5 Otherwise, embed the node
 
 		// Skip 5.5.1
 
@@ -201,6 +310,19 @@ func (api *JsonLdApi) frame(state *FramingContext, nodes map[string]interface{},
 				continue
 			}
 
+			// an empty array is a match none frame: none of the objects are embedded
+			// and the default value is used instead
+			propFrames, _ := framePropVal.([]interface{})
+			if containsProp && len(propFrames) == 0 {
+				continue
+			}
+			subframe := flags
+			if len(propFrames) > 0 {
+				if propFrame, isMap := propFrames[0].(map[string]interface{}); isMap {
+					subframe = propFrame
+				}
+			}
+
 			// add objects
 			value := element[prop].([]interface{})
 
@@ -224,12 +346,6 @@ func (api *JsonLdApi) frame(state *FramingContext, nodes map[string]interface{},
 							// which is global
 							tmp[itemid] = nodeMap[itemid]
 
-							subframe := make(map[string]interface{})
-							if containsProp {
-								subframe = framePropVal.([]map[string]interface{})[0]
-							} else {
-								subframe = flags
-							}
 							api.frame(state, tmp, nodeMap, subframe, list, "@list")
 						} else {
 							// include other values automatically (TODO:
@@ -243,22 +359,57 @@ func (api *JsonLdApi) frame(state *FramingContext, nodes map[string]interface{},
 					// TODO: nodes may need to be node_map, which is
 					// global
 					tmp[itemid] = nodeMap[itemid]
-					subframe := make(map[string]interface{})
-					if containsProp {
-						subframe = framePropVal.([]interface{})[0].(map[string]interface{})
-					} else {
-						subframe = flags
-					}
 					api.frame(state, tmp, nodeMap, subframe, output, prop)
 				} else {
-					// include other values automatically (TODO: may
-					// need JsonLdUtils.clone(o))
-					addFrameOutput(output, prop, item)
+					// JSON-LD 1.1: include other values if they match the value pattern
+					var pattern map[string]interface{}
+					if len(propFrames) > 0 {
+						pattern, _ = propFrames[0].(map[string]interface{})
+					}
+					if valueMatch(pattern, item) {
+						addFrameOutput(output, prop, item)
+					}
 				}
 			}
 
 		}
 
+		// JSON-LD 1.1: embed the nodes which reference this node through
+		// any of the reverse properties in frame
+		if reverseFrame, hasReverse := frame["@reverse"].(map[string]interface{}); hasReverse {
+			reverseOutput := make(map[string]interface{})
+			for _, reverseProp := range GetOrderedKeys(reverseFrame) {
+				subframe := flags
+				if subframes, _ := reverseFrame[reverseProp].([]interface{}); len(subframes) > 0 {
+					if subframeMap, isMap := subframes[0].(map[string]interface{}); isMap {
+						subframe = subframeMap
+					}
+				}
+				for _, subject := range GetOrderedKeys(nodeMap) {
+					node := nodeMap[subject].(map[string]interface{})
+					references := false
+					values, _ := node[reverseProp].([]interface{})
+					for _, v := range values {
+						if vMap, isMap := v.(map[string]interface{}); isMap && vMap["@id"] == id {
+							references = true
+							break
+						}
+					}
+					if !references {
+						continue
+					}
+
+					tmp := map[string]interface{}{subject: node}
+					if _, err := api.frame(state, tmp, nodeMap, subframe, reverseOutput, reverseProp); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if len(reverseOutput) > 0 {
+				output["@reverse"] = reverseOutput
+			}
+		}
+
 		// handle defaults
 		for _, prop := range GetOrderedKeys(frame) {
 			// skip keywords
@@ -266,10 +417,11 @@ func (api *JsonLdApi) frame(state *FramingContext, nodes map[string]interface{},
 				continue
 			}
 
-			pf := frame[prop].([]interface{})
+			// an empty array (match none) has no property frame, the default value is null
+			pf, _ := frame[prop].([]interface{})
 			var propertyFrame map[string]interface{}
 			if len(pf) > 0 {
-				propertyFrame = pf[0].(map[string]interface{})
+				propertyFrame, _ = pf[0].(map[string]interface{})
 			}
 
 			if propertyFrame == nil {
@@ -346,37 +498,31 @@ func getFrameEmbed(frame map[string]interface{}, theDefault Embed) (Embed, error
 	if value == nil {
 		return theDefault, nil
 	}
+	// @embed: true means @once in JSON-LD 1.1, but JSON-LD 1.0 framing embeds the last match
 	if boolVal, isBoolean := value.(bool); isBoolean {
-		if boolVal {
-			return Last, nil
-		} else {
+		if !boolVal {
 			return Never, nil
+		} else if theDefault == Last {
+			return Last, nil
 		}
+		return Once, nil
 	}
 	if embedVal, isEmbed := value.(Embed); isEmbed {
 		return embedVal, nil
 	}
 	if stringVal, isString := value.(string); isString {
-		switch stringVal {
-		case "@always":
-			return Always, nil
-		case "@never":
-			return Never, nil
-		case "@last":
-			return Last, nil
-		case "@link":
-			return Link, nil
-		default:
-			return Last, NewJsonLdError(SyntaxError, "invalid @embed value")
+		switch Embed(stringVal) {
+		case Always, Once, Never, Link, Last:
+			return Embed(stringVal), nil
 		}
 	}
-	return Last, NewJsonLdError(SyntaxError, "invalid @embed value")
+	return Last, NewJsonLdError(InvalidEmbedValue, value)
 }
 
 // removeEmbed removes an existing embed with the given id.
 func removeEmbed(state *FramingContext, id string) {
 	// get existing embed
-	links := state.uniqueEmbeds
+	links := state.uniqueEmbeds[state.graph]
 	embed := links[id]
 	parent := embed.parent
 	property := embed.property
@@ -429,15 +575,18 @@ func removeDependents(embeds map[string]*EmbedNode, id string) {
 }
 
 // FilterNodes returns a map of all of the nodes that match a parsed frame.
-func FilterNodes(nodes map[string]interface{}, frame map[string]interface{}) (map[string]interface{}, error) {
+// nodeMap is used to look up the nodes referenced by nested node patterns.
+func FilterNodes(nodes map[string]interface{}, frame map[string]interface{}, nodeMap map[string]interface{},
+	requireAll bool) (map[string]interface{}, error) {
 	rval := make(map[string]interface{})
 	for id, elementVal := range nodes {
 		element, _ := elementVal.(map[string]interface{})
 		if element != nil {
-			if res, err := FilterNode(element, frame); res {
-				if err != nil {
-					return nil, err
-				}
+			res, err := FilterNode(element, frame, nodeMap, requireAll)
+			if err != nil {
+				return nil, err
+			}
+			if res {
 				rval[id] = element
 			}
 		}
@@ -446,99 +595,275 @@ func FilterNodes(nodes map[string]interface{}, frame map[string]interface{}) (ma
 }
 
 // FilterNode returns true if the given node matches the given frame.
-func FilterNode(node map[string]interface{}, frame map[string]interface{}) (bool, error) {
-	types, _ := frame["@type"]
-	frameIds, _ := frame["@id"]
-
-	// https://json-ld.org/spec/latest/json-ld-framing/#frame-matching
-	//
-	// 1. Node matches if it has an @id property including any IRI or
-	// blank node in the @id property in frame.
-	if frameIds != nil {
-		if _, isString := frameIds.(string); isString {
-			nodeId, _ := node["@id"]
-			if nodeId == nil {
-				return false, nil
+//
+// If requireAll is true, the node must match @id, @type and all non-keyword
+// properties of the frame. Otherwise, matching any of them is enough.
+func FilterNode(node map[string]interface{}, frame map[string]interface{}, nodeMap map[string]interface{},
+	requireAll bool) (bool, error) {
+	// https://www.w3.org/TR/json-ld11-framing/#frame-matching
+
+	// 2. Node matches if frame has no non-keyword properties.
+	wildcard := true
+	matchesSome := false
+
+	for _, key := range GetOrderedKeys(frame) {
+		matchThis := false
+
+		if key == "@id" {
+			// 1. Node matches if it has an @id property including any IRI or
+			// blank node in the @id property in frame.
+			nodeID := node["@id"]
+			switch frameIds := frame["@id"].(type) {
+			case string:
+				matchThis = nodeID != nil && DeepCompare(nodeID, frameIds, false)
+			case []interface{}:
+				matchThis = nodeID != nil && deepContains(frameIds, nodeID)
+			case map[string]interface{}:
+				// JSON-LD 1.1: wildcard matches any node
+				matchThis = true
+			default:
+				return false, NewJsonLdError(SyntaxError, "frame @id must be an array")
 			}
-			if DeepCompare(nodeId, frameIds, false) {
-				return true, nil
+			if !requireAll {
+				return matchThis, nil
 			}
-		} else {
-			frameIdList, isList := frameIds.([]interface{})
+		} else if key == "@type" {
+			// 3.1 If property is @type:
+			wildcard = false
+			typesList, isList := frame["@type"].([]interface{})
 			if !isList {
-				return false, NewJsonLdError(SyntaxError, "frame @id must be an array")
-			} else {
-				nodeId, _ := node["@id"]
-				if nodeId == nil {
+				return false, NewJsonLdError(SyntaxError, "frame @type must be an array")
+			}
+			nodeTypesVal, nodeHasType := node["@type"]
+			var nodeTypes []interface{}
+			if !nodeHasType {
+				nodeTypes = make([]interface{}, 0)
+			} else if nodeTypes, isList = nodeTypesVal.([]interface{}); !isList {
+				return false, NewJsonLdError(SyntaxError, "node @type must be an array")
+			}
+			if len(typesList) == 0 {
+				// 3.1.4 Property matches if values is empty and the @type property in frame is match none.
+				if len(nodeTypes) > 0 {
 					return false, nil
 				}
-				for _, j := range frameIdList {
-					if DeepCompare(nodeId, j, false) {
-						return true, nil
+				matchThis = true
+			} else if len(typesList) == 1 && isEmptyObject(typesList[0]) {
+				// 3.1.3 Property matches if the @type property in frame is a wildcard
+				// and the node has any type.
+				matchThis = len(nodeTypes) > 0
+			} else {
+				for _, j := range typesList {
+					if jMap, isMap := j.(map[string]interface{}); isMap {
+						// 3.1.2 Property matches if the @type property in frame is a default object.
+						if _, hasDefault := jMap["@default"]; hasDefault {
+							matchThis = true
+						}
+					} else if deepContains(nodeTypes, j) {
+						// 3.1.1 Property matches if the @type property in frame includes any IRI in values.
+						matchThis = true
 					}
 				}
 			}
-		}
-		return false, nil
-	}
-	// 2. Node matches if frame has no non-keyword properties.TODO
-	// 3.1 If property is @type:
-	if types != nil {
-		typesList, isList := types.([]interface{})
-		if !isList {
-			return false, NewJsonLdError(SyntaxError, "frame @type must be an array")
-		}
-		nodeTypesVal, nodeHasType := node["@type"]
-		var nodeTypes []interface{}
-		if !nodeHasType {
-			nodeTypes = make([]interface{}, 0)
-		} else if nodeTypes, isList = nodeTypesVal.([]interface{}); !isList {
-			return false, NewJsonLdError(SyntaxError, "node @type must be an array")
-		}
-		// 3.1.1 Property matches if the @type property in frame includes any IRI in values.
-		for _, i := range nodeTypes {
-			for _, j := range typesList {
-				if DeepCompare(i, j, false) {
-					return true, nil
-				}
+			if !requireAll {
+				return matchThis, nil
 			}
-		}
-		// TODO: 3.1.2
-		// 3.1.3 Otherwise, property matches if values is empty and the @type property in frame is match none.
-		if len(typesList) == 1 {
-			vMap, isMap := typesList[0].(map[string]interface{})
-			if isMap && len(vMap) == 0 {
-				return len(nodeTypes) > 0, nil
+		} else if IsKeyword(key) {
+			continue
+		} else {
+			// 3.2
+			wildcard = false
+			propertyFrames, _ := frame[key].([]interface{})
+			nodeValues, _ := node[key].([]interface{})
+			var propertyFrame map[string]interface{}
+			if len(propertyFrames) > 0 {
+				propertyFrame, _ = propertyFrames[0].(map[string]interface{})
 			}
-		}
-		// 3.1.4 Otherwise, property does not match.
-		return false, nil
-	}
-
-	// 3.2
-	for _, key := range GetKeys(frame) {
-		_, nodeContainsKey := node[key]
-		if !IsKeyword(key) && !nodeContainsKey {
-			frameObject := frame[key]
-			if oList, isList := frameObject.([]interface{}); isList {
-				_default := false
-				for _, obj := range oList {
-					if oMap, isMap := obj.(map[string]interface{}); isMap {
-						if _, containsKey := oMap["@default"]; containsKey {
-							_default = true
-						}
+
+			// missing properties with a default value don't affect matching
+			if _, hasDefault := propertyFrame["@default"]; hasDefault && len(nodeValues) == 0 {
+				continue
+			}
+
+			if len(propertyFrames) == 0 {
+				// 3.4 Property matches if values is empty and the property in frame is match none.
+				if len(nodeValues) > 0 {
+					return false, nil
+				}
+				matchThis = true
+			} else if listPatterns, isList := propertyFrame["@list"].([]interface{}); isList {
+				// 3.8 Property matches if values is a list with any item matching the list pattern.
+				if len(listPatterns) > 0 && len(nodeValues) > 0 {
+					listPattern, _ := listPatterns[0].(map[string]interface{})
+					nodeList, _ := nodeValues[0].(map[string]interface{})["@list"].([]interface{})
+					var err error
+					if matchThis, err = matchesAny(listPattern, nodeList, nodeMap, requireAll); err != nil {
+						return false, err
 					}
 				}
-				if _default {
-					continue
+			} else if IsValue(propertyFrame) || isNodePattern(propertyFrame) {
+				// 3.6 + 3.7 Property matches if any value matches the value or node pattern.
+				var err error
+				if matchThis, err = matchesAny(propertyFrame, nodeValues, nodeMap, requireAll); err != nil {
+					return false, err
 				}
+			} else {
+				// 3.5 Property matches if values is not empty and the property in frame is a wildcard.
+				matchThis = len(nodeValues) > 0
 			}
+		}
 
+		// 3.9 If requireAll is true, node matches only if all properties match.
+		if !matchThis && requireAll {
 			return false, nil
 		}
+		matchesSome = matchesSome || matchThis
+	}
+
+	// 4. Node matches if frame has no non-keyword properties or if any property matches.
+	return wildcard || matchesSome, nil
+}
+
+// matchesAny returns true if any of the given values matches the given value or node pattern.
+func matchesAny(pattern map[string]interface{}, values []interface{}, nodeMap map[string]interface{},
+	requireAll bool) (bool, error) {
+	for _, value := range values {
+		if IsValue(pattern) {
+			if valueMatch(pattern, value) {
+				return true, nil
+			}
+			continue
+		}
+		// node patterns are matched against the referenced node
+		valueMap, _ := value.(map[string]interface{})
+		id, hasID := valueMap["@id"].(string)
+		if !hasID || IsValue(value) {
+			continue
+		}
+		node, _ := nodeMap[id].(map[string]interface{})
+		if node == nil {
+			continue
+		}
+		matches, err := FilterNode(node, pattern, nodeMap, GetFrameFlag(pattern, "@requireAll", requireAll))
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// valueMatch returns true if the given value matches the value pattern.
+// The @value, @type and @language of the pattern may be a single value, an array
+// of values, an empty array (match none) or an empty object (wildcard).
+func valueMatch(pattern map[string]interface{}, value interface{}) bool {
+	valueMap, _ := value.(map[string]interface{})
+	values := patternValues(pattern, "@value")
+	types := patternValues(pattern, "@type")
+	languages := patternValues(pattern, "@language")
+
+	if len(values) == 0 && len(types) == 0 && len(languages) == 0 {
+		return true
+	}
+	if !IsValue(value) {
+		return false
+	}
+
+	if !deepContains(values, valueMap["@value"]) && !(len(values) > 0 && isEmptyObject(values[0])) {
+		return false
+	}
+	for _, key := range []string{"@type", "@language"} {
+		patternVals := types
+		if key == "@language" {
+			patternVals = languages
+		}
+		v, hasKey := valueMap[key]
+		if !hasKey && len(patternVals) == 0 {
+			continue
+		}
+		if !deepContains(patternVals, v) && !(hasKey && len(patternVals) > 0 && isEmptyObject(patternVals[0])) {
+			return false
+		}
+	}
+	return true
+}
+
+// patternValues returns the values of the given key in a value pattern as an array.
+func patternValues(pattern map[string]interface{}, key string) []interface{} {
+	value, hasKey := pattern[key]
+	if !hasKey || value == nil {
+		return []interface{}{}
 	}
+	if valueList, isList := value.([]interface{}); isList {
+		return valueList
+	}
+	return []interface{}{value}
+}
+
+// isNodePattern returns true if the given frame restricts the matched node
+// by @id, @type or any of its properties.
+func isNodePattern(frame map[string]interface{}) bool {
+	for key := range frame {
+		if key == "@id" || key == "@type" || !IsKeyword(key) {
+			return true
+		}
+	}
+	return false
+}
 
-	return true, nil
+// isEmptyObject returns true if the given value is an empty JSON object.
+func isEmptyObject(v interface{}) bool {
+	vMap, isMap := v.(map[string]interface{})
+	return isMap && len(vMap) == 0
+}
+
+// PruneBlankNodeIdentifiers removes @id from all node objects in the framed
+// output whose blank node identifier is used only once.
+func PruneBlankNodeIdentifiers(framed interface{}) {
+	counts := make(map[string]int)
+	countBlankNodeIdentifiers(framed, counts)
+	removeBlankNodeIdentifiers(framed, counts)
+}
+
+func countBlankNodeIdentifiers(input interface{}, counts map[string]int) {
+	if inputList, isList := input.([]interface{}); isList {
+		for _, item := range inputList {
+			countBlankNodeIdentifiers(item, counts)
+		}
+	} else if inputMap, isMap := input.(map[string]interface{}); isMap {
+		for key, value := range inputMap {
+			if key == "@id" || key == "@type" {
+				// blank nodes may also be used as types
+				ids, isList := value.([]interface{})
+				if !isList {
+					ids = []interface{}{value}
+				}
+				for _, id := range ids {
+					if idStr, isString := id.(string); isString && strings.HasPrefix(idStr, "_:") {
+						counts[idStr]++
+					}
+				}
+			} else {
+				countBlankNodeIdentifiers(value, counts)
+			}
+		}
+	}
+}
+
+func removeBlankNodeIdentifiers(input interface{}, counts map[string]int) {
+	if inputList, isList := input.([]interface{}); isList {
+		for _, item := range inputList {
+			removeBlankNodeIdentifiers(item, counts)
+		}
+	} else if inputMap, isMap := input.(map[string]interface{}); isMap {
+		if idStr, isString := inputMap["@id"].(string); isString && counts[idStr] == 1 {
+			delete(inputMap, "@id")
+		}
+		for _, value := range inputMap {
+			removeBlankNodeIdentifiers(value, counts)
+		}
+	}
 }
 
 // addFrameOutput adds framing output to the given parent.
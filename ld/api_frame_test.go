@@ -39,3 +39,19 @@ func TestGetFrameFlag(t *testing.T) {
 	),
 	)
 }
+
+func TestFrameDoesNotModifyOptions(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+
+	input := map[string]interface{}{
+		"@id":                  "http://example.org/a",
+		"http://example.org/p": "value",
+	}
+	frame := map[string]interface{}{
+		"@graph": map[string]interface{}{},
+	}
+	_, err := proc.Frame(input, frame, opts)
+	assert.Nil(t, err)
+	assert.False(t, opts.FrameDefault)
+}
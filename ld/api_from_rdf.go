@@ -2,6 +2,7 @@ package ld
 
 import (
 	"sort"
+	"strings"
 )
 
 // UsagesNode is a helper class for node usages
@@ -79,6 +80,45 @@ func (nmn *NodeMapNode) IsWellFormedListNode() bool {
 	return true
 }
 
+// compoundLiteralValue returns the string with a base direction represented by this node
+// if it is a compound literal (see JsonLdOptions.RdfDirection), or nil otherwise.
+func (nmn *NodeMapNode) compoundLiteralValue() map[string]interface{} {
+	rval := make(map[string]interface{})
+	for property, values := range nmn.Values {
+		if property == "@id" {
+			continue
+		}
+		valueList, isList := values.([]interface{})
+		if !isList || len(valueList) != 1 {
+			return nil
+		}
+		valueMap, _ := valueList[0].(map[string]interface{})
+		literal, isString := valueMap["@value"].(string)
+		if !isString || len(valueMap) != 1 {
+			return nil
+		}
+		switch property {
+		case RDFValue:
+			rval["@value"] = literal
+		case RDFLanguage:
+			rval["@language"] = literal
+		case RDFDirection:
+			if !isValidDirection(literal) {
+				return nil
+			}
+			rval["@direction"] = literal
+		default:
+			return nil
+		}
+	}
+	_, hasValue := rval["@value"]
+	_, hasDirection := rval["@direction"]
+	if !hasValue || !hasDirection {
+		return nil
+	}
+	return rval
+}
+
 // Serialize returns this node without the usages variable
 func (nmn *NodeMapNode) Serialize() map[string]interface{} {
 	rval := make(map[string]interface{}, len(nmn.Values))
@@ -138,7 +178,10 @@ func (api *JsonLdApi) FromRDF(dataset *RDFDataset, opts *JsonLdOptions) ([]inter
 			}
 
 			// 3.5.5)
-			value, _ := rdfToObject(object, opts.UseNativeTypes)
+			value, err := rdfToObject(object, opts.UseNativeTypes, opts.ProcessingMode, opts.RdfDirection)
+			if err != nil {
+				return nil, err
+			}
 
 			// 3.5.6+7)
 			MergeValue(node.Values, predicate, value)
@@ -160,6 +203,25 @@ func (api *JsonLdApi) FromRDF(dataset *RDFDataset, opts *JsonLdOptions) ([]inter
 		}
 	}
 
+	// JSON-LD 1.1: replace references to compound literals with strings with a base direction
+	if opts.RdfDirection == RdfDirectionCompoundLiteral {
+		for _, graph := range graphMap {
+			for id, node := range graph {
+				usage := referencedOnceMap[id]
+				if _, isGraphName := graphMap[id]; usage == nil || isGraphName || !strings.HasPrefix(id, "_:") {
+					continue
+				}
+				if value := node.compoundLiteralValue(); value != nil {
+					delete(usage.value, "@id")
+					for k, v := range value {
+						usage.value[k] = v
+					}
+					delete(graph, id)
+				}
+			}
+		}
+	}
+
 	// 4)
 	for _, graph := range graphMap {
 		// 4.1), 4.2)
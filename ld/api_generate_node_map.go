@@ -166,7 +166,17 @@ func (api *JsonLdApi) GenerateNodeMap(element interface{}, nodeMap map[string]in
 		// 6.10)
 		if graphVal, hasGraph := elem["@graph"]; hasGraph {
 			delete(elem, "@graph")
-			api.GenerateNodeMap(graphVal, nodeMap, id, nil, "", nil, issuer)
+			if err := api.GenerateNodeMap(graphVal, nodeMap, id, nil, "", nil, issuer); err != nil {
+				return err
+			}
+		}
+
+		// JSON-LD 1.1: included nodes are added to the node map as top-level nodes
+		if includedVal, hasIncluded := elem["@included"]; hasIncluded {
+			delete(elem, "@included")
+			if err := api.GenerateNodeMap(includedVal, nodeMap, activeGraph, nil, "", nil, issuer); err != nil {
+				return err
+			}
 		}
 
 		// 6.11)
@@ -1,7 +1,9 @@
 package ld
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -13,6 +15,8 @@ type Context struct {
 	options         *JsonLdOptions
 	termDefinitions map[string]interface{}
 	inverse         map[string]interface{}
+	protected       map[string]bool
+	previousContext *Context
 }
 
 // NewContext creates and returns a new Context object.
@@ -35,6 +39,7 @@ func NewContext(values map[string]interface{}, options *JsonLdOptions) *Context
 	}
 
 	context.termDefinitions = make(map[string]interface{})
+	context.protected = make(map[string]bool)
 
 	return context
 }
@@ -47,10 +52,12 @@ func CopyContext(ctx *Context) *Context {
 		context.termDefinitions[k] = v
 	}
 
-	for k, v := range ctx.inverse {
-		context.inverse[k] = v
+	for k, v := range ctx.protected {
+		context.protected[k] = v
 	}
 
+	context.previousContext = ctx.previousContext
+
 	return context
 }
 
@@ -58,14 +65,31 @@ func CopyContext(ctx *Context) *Context {
 // returns a new active context.
 // Refer to http://www.w3.org/TR/json-ld-api/#context-processing-algorithms for details
 func (c *Context) Parse(localContext interface{}) (*Context, error) {
-	return c.parse(localContext, make([]string, 0))
+	return c.parse(localContext, make([]string, 0), false, true, true)
 }
 
 // parse processes a local context, retrieving any URLs as necessary, and
-// returns a new active context
-func (c *Context) parse(localContext interface{}, remoteContexts []string) (*Context, error) {
+// returns a new active context.
+// If overrideProtected is true, protected term definitions may be redefined
+// (this is the case for property-scoped contexts).
+// If propagate is false, the resulting context remembers the active context
+// so that it can be reverted when descending into node objects (this is the case
+// for type-scoped contexts).
+// If validateScopedContext is false, scoped contexts in term definitions are not validated.
+func (c *Context) parse(localContext interface{}, remoteContexts []string, overrideProtected bool,
+	propagate bool, validateScopedContext bool) (*Context, error) {
+	// JSON-LD 1.1: a local context may override the propagate flag
+	if localContextMap, isMap := localContext.(map[string]interface{}); isMap {
+		if propagateValue, isBool := localContextMap["@propagate"].(bool); isBool {
+			propagate = propagateValue
+		}
+	}
+
 	// 1. Initialize result to the result of cloning active context.
 	result := CopyContext(c)
+	if !propagate && result.previousContext == nil {
+		result.previousContext = c
+	}
 
 	// 2)
 	localContextList, isArray := localContext.([]interface{})
@@ -77,7 +101,22 @@ func (c *Context) parse(localContext interface{}, remoteContexts []string) (*Con
 	for _, context := range localContextList {
 		// 3.1)
 		if context == nil {
-			result = NewContext(nil, c.options)
+			// JSON-LD 1.1: protected terms can only be cleared by a property-scoped context
+			if !overrideProtected && len(result.protected) > 0 {
+				return nil, NewJsonLdError(InvalidContextNullification,
+					"tried to nullify a context with protected terms outside of a term definition")
+			}
+			// the processing mode is a property of the processor,
+			// so it survives the reset
+			mode, hasMode := result.values["processingMode"]
+			newResult := NewContext(nil, c.options)
+			if hasMode {
+				newResult.values["processingMode"] = mode
+			}
+			if !propagate {
+				newResult.previousContext = result
+			}
+			result = newResult
 			continue
 		}
 
@@ -112,7 +151,7 @@ func (c *Context) parse(localContext interface{}, remoteContexts []string) (*Con
 			}
 
 			// 3.2.4
-			resultRef, err := result.parse(context, remoteContexts)
+			resultRef, err := result.parse(context, remoteContexts, overrideProtected, true, validateScopedContext)
 			if err != nil {
 				return nil, err
 			}
@@ -126,6 +165,38 @@ func (c *Context) parse(localContext interface{}, remoteContexts []string) (*Con
 			return nil, NewJsonLdError(InvalidLocalContext, context)
 		}
 
+		// JSON-LD 1.1: @version
+		if versionValue, versionPresent := contextMap["@version"]; versionPresent {
+			if !isVersion11(versionValue) {
+				return nil, NewJsonLdError(InvalidVersionValue,
+					fmt.Sprintf("unsupported JSON-LD version: %v", versionValue))
+			}
+			if c.options.ProcessingMode == JsonLd_1_0 {
+				return nil, NewJsonLdError(ProcessingModeConflict,
+					fmt.Sprintf("@version: %v is not compatible with %s", versionValue, c.options.ProcessingMode))
+			}
+			result.values["processingMode"] = JsonLd_1_1
+		}
+
+		// JSON-LD 1.1: @import
+		if importValue, importPresent := contextMap["@import"]; importPresent {
+			importedContext, err := result.loadImportedContext(importValue)
+			if err != nil {
+				return nil, err
+			}
+			// entries of the local context replace the imported ones
+			mergedContext := make(map[string]interface{}, len(importedContext)+len(contextMap))
+			for k, v := range importedContext {
+				mergedContext[k] = v
+			}
+			for k, v := range contextMap {
+				if k != "@import" {
+					mergedContext[k] = v
+				}
+			}
+			contextMap = mergedContext
+		}
+
 		// 3.4
 		baseValue, basePresent := contextMap["@base"]
 		if len(remoteContexts) == 0 && basePresent {
@@ -172,14 +243,47 @@ func (c *Context) parse(localContext interface{}, remoteContexts []string) (*Con
 			}
 		}
 
+		// JSON-LD 1.1: default base direction
+		if directionValue, directionPresent := contextMap["@direction"]; directionPresent {
+			if !result.processingMode(1.1) {
+				return nil, NewJsonLdError(InvalidContextEntry, "@direction is not allowed in JSON-LD 1.0")
+			}
+			if directionValue == nil {
+				delete(result.values, "@direction")
+			} else if isValidDirection(directionValue) {
+				result.values["@direction"] = directionValue
+			} else {
+				return nil, NewJsonLdError(InvalidBaseDirection, directionValue)
+			}
+		}
+
+		// JSON-LD 1.1: @protected
+		if protectedValue, protectedPresent := contextMap["@protected"]; protectedPresent {
+			if _, isBool := protectedValue.(bool); !isBool {
+				return nil, NewJsonLdError(InvalidProtectedValue, "@protected value must be a boolean")
+			}
+		}
+
+		// JSON-LD 1.1: @propagate
+		if propagateValue, propagatePresent := contextMap["@propagate"]; propagatePresent {
+			if !result.processingMode(1.1) {
+				return nil, NewJsonLdError(InvalidContextEntry, "@propagate is not allowed in JSON-LD 1.0")
+			}
+			if _, isBool := propagateValue.(bool); !isBool {
+				return nil, NewJsonLdError(InvalidPropagateValue, "@propagate value must be a boolean")
+			}
+		}
+
 		// 3.7
 		defined := make(map[string]bool)
 
 		for key := range contextMap {
-			if key == "@base" || key == "@vocab" || key == "@language" {
+			if key == "@base" || key == "@vocab" || key == "@language" || key == "@version" ||
+				key == "@protected" || key == "@propagate" || key == "@direction" {
 				continue
 			}
-			if err := result.createTermDefinition(contextMap, key, defined); err != nil {
+			err := result.createTermDefinition(contextMap, key, defined, overrideProtected, validateScopedContext)
+			if err != nil {
 				return nil, err
 			}
 		}
@@ -188,6 +292,62 @@ func (c *Context) parse(localContext interface{}, remoteContexts []string) (*Con
 	return result, nil
 }
 
+// loadImportedContext dereferences the value of @import and returns the context it refers to.
+func (c *Context) loadImportedContext(importValue interface{}) (map[string]interface{}, error) {
+	if !c.processingMode(1.1) {
+		return nil, NewJsonLdError(InvalidContextEntry, "@import is not allowed in JSON-LD 1.0")
+	}
+	importStr, isString := importValue.(string)
+	if !isString {
+		return nil, NewJsonLdError(InvalidImportValue, "@import value must be a string")
+	}
+	uri := Resolve(c.values["@base"].(string), importStr)
+	rd, err := c.options.DocumentLoader.LoadDocument(uri)
+	if err != nil {
+		return nil, NewJsonLdError(LoadingRemoteContextFailed,
+			fmt.Sprintf("Dereferencing a URL did not result in a valid JSON-LD context: %s", uri))
+	}
+	remoteContextMap, isMap := rd.Document.(map[string]interface{})
+	importedContext, isContextMap := remoteContextMap["@context"].(map[string]interface{})
+	if !isMap || !isContextMap {
+		return nil, NewJsonLdError(InvalidRemoteContext,
+			fmt.Sprintf("imported context must be a single context definition: %s", uri))
+	}
+	if _, hasImport := importedContext["@import"]; hasImport {
+		return nil, NewJsonLdError(InvalidContextEntry, "an imported context must not include @import")
+	}
+	return importedContext, nil
+}
+
+// isVersion11 returns true if the given @version value is the number 1.1.
+func isVersion11(v interface{}) bool {
+	switch version := v.(type) {
+	case float64:
+		return version == 1.1
+	case json.Number:
+		return version.String() == "1.1"
+	default:
+		return false
+	}
+}
+
+// warn reports a warning through the WarningHandler option, if set.
+func (c *Context) warn(format string, args ...interface{}) {
+	if c.options != nil && c.options.WarningHandler != nil {
+		c.options.WarningHandler(fmt.Sprintf(format, args...))
+	}
+}
+
+// processingMode returns true if the processor runs in the given mode (1.0 or 1.1) or later.
+// Processing mode is taken from the options unless a context switched it using @version.
+func (c *Context) processingMode(version float64) bool {
+	mode := c.options.ProcessingMode
+	if modeVal, hasMode := c.values["processingMode"]; hasMode {
+		mode = modeVal.(string)
+	}
+	return isProcessingMode(mode, version)
+}
+
 // CompactValue performs value compaction on an object with @value or @id as the only property.
 // See http://www.w3.org/TR/json-ld-api/#value-compaction
 func (c *Context) CompactValue(activeProperty string, value map[string]interface{}) interface{} {
@@ -195,9 +355,24 @@ func (c *Context) CompactValue(activeProperty string, value map[string]interface
 	numberMembers := len(value)
 	// 2)
 	_, containsIndex := value["@index"]
-	if containsIndex && c.GetContainer(activeProperty) == "@index" {
+	if containsIndex && c.HasContainerMapping(activeProperty, "@index") {
 		numberMembers--
 	}
+	// JSON-LD 1.1: strings with a base direction compact to plain strings only if
+	// both language and direction match those of the active property
+	if directionVal, hasDirection := value["@direction"]; hasDirection {
+		_, hasLang := value["@language"]
+		if numberMembers == 2 || (numberMembers == 3 && hasLang) {
+			language := c.values["@language"]
+			if langVal, termHasLang := c.GetTermDefinition(activeProperty)["@language"]; termHasLang {
+				language = langVal
+			}
+			if directionVal == c.GetDirection(activeProperty) && value["@language"] == language {
+				return value["@value"]
+			}
+		}
+		return value
+	}
 	// 3)
 	if numberMembers > 2 {
 		return value
@@ -224,6 +399,10 @@ func (c *Context) CompactValue(activeProperty string, value map[string]interface
 	if hasType && typeVal == typeMapping {
 		return valueValue
 	}
+	// JSON-LD 1.1: a base direction would be added to the string on expansion
+	if _, isString := valueValue.(string); isString && !hasType && c.GetDirection(activeProperty) != "" {
+		return value
+	}
 	// 6)
 	langVal, hasLang := value["@language"]
 	if hasLang {
@@ -249,8 +428,12 @@ func (c *Context) CompactValue(activeProperty string, value map[string]interface
 // createTermDefinition creates a term definition in the active context
 // for a term being processed in a local context as described in
 // http://www.w3.org/TR/json-ld-api/#create-term-definition
+//
+// If overrideProtected is false, an attempt to change a protected term definition
+// results in an error. If validateScopedContext is true, a scoped context
+// of the term definition is processed to make sure it is valid.
 func (c *Context) createTermDefinition(context map[string]interface{}, term string,
-	defined map[string]bool) error {
+	defined map[string]bool, overrideProtected bool, validateScopedContext bool) error {
 	if definedValue, inDefined := defined[term]; inDefined {
 		if definedValue {
 			return nil
@@ -260,21 +443,50 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 
 	defined[term] = false
 
+	value := context[term]
+	mapValue, isMap := value.(map[string]interface{})
+
 	if IsKeyword(term) {
-		return NewJsonLdError(KeywordRedefinition, term)
+		// JSON-LD 1.1 allows @type to be protected and to have a @set container
+		if term != "@type" || !c.processingMode(1.1) || !isMap || !isValidTypeKeywordDefinition(mapValue) {
+			return NewJsonLdError(KeywordRedefinition, term)
+		}
 	}
 
+	// JSON-LD 1.1: terms which look like keywords are reserved for future use
+	if keywordForm.MatchString(term) && !IsKeyword(term) {
+		c.warn("Terms beginning with '@' are reserved for future use and ignored: %s", term)
+		defined[term] = true
+		return nil
+	}
+
+	// the previous definition is needed to check protected terms
+	previousDefinition := c.termDefinitions[term]
+	wasProtected := c.protected[term]
 	delete(c.termDefinitions, term)
-	value := context[term]
-	mapValue, isMap := value.(map[string]interface{})
+	delete(c.protected, term)
+
+	// JSON-LD 1.1: a term is protected if its own definition or the context says so
+	protected := context["@protected"] == true
+	if protectedValue, hasProtected := mapValue["@protected"]; hasProtected {
+		if !c.processingMode(1.1) {
+			return NewJsonLdError(InvalidTermDefinition, "@protected is not allowed in JSON-LD 1.0")
+		}
+		protectedBool, isBool := protectedValue.(bool)
+		if !isBool {
+			return NewJsonLdError(InvalidProtectedValue, "@protected value must be a boolean")
+		}
+		protected = protectedBool
+	}
+
 	idValue, hasID := mapValue["@id"]
 	if value == nil || (isMap && hasID && idValue == nil) {
-		c.termDefinitions[term] = nil
-		defined[term] = true
-		return nil
+		return c.setTermDefinition(term, nil, defined, protected, wasProtected && !overrideProtected,
+			previousDefinition)
 	}
 
-	if _, isString := value.(string); isString {
+	_, simpleTerm := value.(string)
+	if simpleTerm {
 		mapValue = map[string]interface{}{"@id": value}
 		isMap = true
 	}
@@ -286,6 +498,16 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 	// casting the value so it doesn't have to be done below everytime
 	val := mapValue
 
+	// JSON-LD 1.1: expanded term definitions may only contain known keys
+	if c.processingMode(1.1) {
+		for key := range val {
+			if !termDefinitionKeys[key] {
+				return NewJsonLdError(InvalidTermDefinition,
+					fmt.Sprintf("unexpected key %s in the definition of term %s", key, term))
+			}
+		}
+	}
+
 	// 9) create a new term definition
 	var definition = make(map[string]interface{})
 
@@ -295,7 +517,7 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 		if !isString {
 			return NewJsonLdError(InvalidTypeMapping, typeValue)
 		}
-		typeIri, err := c.ExpandIri(typeStr, false, true, context, defined)
+		typeIri, err := c.expandIri(typeStr, false, true, context, defined, overrideProtected)
 		if err != nil {
 			if err.(*JsonLdError).Code != InvalidIRIMapping {
 				return err
@@ -305,7 +527,9 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 
 		// TODO: fix check for absoluteIri (blank nodes shouldn't count, at
 		// least not here!)
-		if typeIri == "@id" || typeIri == "@vocab" || (!strings.HasPrefix(typeIri, "_:") && IsAbsoluteIri(typeIri)) {
+		// JSON-LD 1.1 adds @json type mapping for JSON literals
+		if typeIri == "@id" || typeIri == "@vocab" || (typeIri == "@json" && c.processingMode(1.1)) ||
+			(!strings.HasPrefix(typeIri, "_:") && IsAbsoluteIri(typeIri)) {
 			definition["@type"] = typeIri
 		} else {
 			return NewJsonLdError(InvalidTypeMapping, typeIri)
@@ -314,7 +538,9 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 
 	// 11)
 	if reverseValue, present := val["@reverse"]; present {
-		if _, idPresent := val["@id"]; idPresent {
+		_, idPresent := val["@id"]
+		_, nestPresent := val["@nest"]
+		if idPresent || nestPresent {
 			return NewJsonLdError(InvalidReverseProperty, val)
 		}
 		reverseStr, isString := reverseValue.(string)
@@ -322,7 +548,7 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 			return NewJsonLdError(InvalidIRIMapping,
 				"Expected string for @reverse value. got "+fmt.Sprintf("%v", reverseValue))
 		}
-		reverse, err := c.ExpandIri(reverseStr, false, true, context, defined)
+		reverse, err := c.expandIri(reverseStr, false, true, context, defined, overrideProtected)
 		if err != nil {
 			return err
 		}
@@ -330,19 +556,17 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 			return NewJsonLdError(InvalidIRIMapping, "Non-absolute @reverse IRI: "+reverse)
 		}
 		definition["@id"] = reverse
-		if containerValue, present := val["@container"]; present {
-			container := containerValue.(string)
-			if container == "" || container == "@set" || container == "@index" {
-				definition["@container"] = container
+		if containerValue, present := val["@container"]; present && containerValue != nil {
+			if containerValue == "@set" || containerValue == "@index" {
+				definition["@container"] = []interface{}{containerValue}
 			} else {
 				return NewJsonLdError(InvalidReverseProperty,
 					"reverse properties only support set- and index-containers")
 			}
 		}
 		definition["@reverse"] = true
-		c.termDefinitions[term] = definition
-		defined[term] = true
-		return nil
+		return c.setTermDefinition(term, definition, defined, protected, wasProtected && !overrideProtected,
+			previousDefinition)
 	}
 
 	// 12)
@@ -354,8 +578,14 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 		if !isString {
 			return NewJsonLdError(InvalidIRIMapping, "expected value of @id to be a string")
 		}
+		// JSON-LD 1.1: IRI mappings which look like keywords are reserved for future use
+		if keywordForm.MatchString(idStr) && !IsKeyword(idStr) {
+			c.warn("Values beginning with '@' are reserved for future use and ignored: %s", idStr)
+			defined[term] = true
+			return nil
+		}
 
-		res, err := c.ExpandIri(idStr, false, true, context, defined)
+		res, err := c.expandIri(idStr, false, true, context, defined, overrideProtected)
 		if err != nil {
 			return err
 		}
@@ -368,12 +598,19 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 			return NewJsonLdError(InvalidIRIMapping,
 				"resulting IRI mapping should be a keyword, absolute IRI or blank node")
 		}
+		// JSON-LD 1.1: simple terms whose IRI ends with a gen-delim character may be used as prefixes
+		if simpleTerm && !strings.ContainsAny(term, ":/") &&
+			(strings.ContainsAny(res[len(res)-1:], ":/?#[]@") || strings.HasPrefix(res, "_:")) {
+			definition["@prefix"] = true
+		}
 		// 14)
+	} else if term == "@type" {
+		definition["@id"] = term
 	} else if colIndex := strings.Index(term, ":"); colIndex >= 0 {
 		prefix := term[0:colIndex]
 		suffix := term[colIndex+1:]
 		if _, containsPrefix := context[prefix]; containsPrefix {
-			if err := c.createTermDefinition(context, prefix, defined); err != nil {
+			if err := c.createTermDefinition(context, prefix, defined, overrideProtected, validateScopedContext); err != nil {
 				return err
 			}
 		}
@@ -392,12 +629,80 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 
 	// 16)
 	if containerVal, hasContainer := val["@container"]; hasContainer {
-		container := containerVal.(string)
-		if container != "@list" && container != "@set" && container != "@index" && container != "@language" {
+		// JSON-LD 1.1: a container mapping may be an array, such as [@graph, @index]
+		container, isList := containerVal.([]interface{})
+		if !isList {
+			container = []interface{}{containerVal}
+		}
+		if (isList && !c.processingMode(1.1)) || !isValidContainerMapping(container, c.processingMode(1.1)) {
+			if c.processingMode(1.1) {
+				return NewJsonLdError(InvalidContainerMapping, "invalid @container value")
+			}
 			return NewJsonLdError(InvalidContainerMapping,
 				"@container must be either @list, @set, @index, or @language")
 		}
 		definition["@container"] = container
+		// JSON-LD 1.1: the values of a type map are node references
+		for _, v := range container {
+			if v != "@type" {
+				continue
+			}
+			if typeValue, hasTypeMapping := definition["@type"]; !hasTypeMapping {
+				definition["@type"] = "@id"
+			} else if typeValue != "@id" && typeValue != "@vocab" {
+				return NewJsonLdError(InvalidTypeMapping, "type mapping for a type map must be @id or @vocab")
+			}
+		}
+	}
+
+	// JSON-LD 1.1: property-valued indexes
+	if indexVal, hasIndex := val["@index"]; hasIndex {
+		if !c.processingMode(1.1) {
+			return NewJsonLdError(InvalidTermDefinition, "@index is not allowed in JSON-LD 1.0")
+		}
+		container, _ := definition["@container"].([]interface{})
+		if !deepContains(container, "@index") {
+			return NewJsonLdError(InvalidTermDefinition, "@index requires an @index container")
+		}
+		index, isString := indexVal.(string)
+		if !isString || IsKeyword(index) {
+			return NewJsonLdError(InvalidTermDefinition, "@index value must be a property")
+		}
+		expandedIndex, err := c.expandIri(index, false, true, context, defined, overrideProtected)
+		if err != nil {
+			return err
+		}
+		if !IsAbsoluteIri(expandedIndex) || strings.HasPrefix(expandedIndex, "_:") {
+			return NewJsonLdError(InvalidTermDefinition, "@index value must expand to an IRI")
+		}
+		definition["@index"] = index
+	}
+
+	// JSON-LD 1.1: @prefix
+	if prefixVal, hasPrefix := val["@prefix"]; hasPrefix {
+		if !c.processingMode(1.1) || strings.ContainsAny(term, ":/") {
+			return NewJsonLdError(InvalidTermDefinition, "@prefix is not allowed for term "+term)
+		}
+		prefix, isBool := prefixVal.(bool)
+		if !isBool {
+			return NewJsonLdError(InvalidPrefixValue, "@prefix value must be a boolean")
+		}
+		if prefix && IsKeyword(definition["@id"]) {
+			return NewJsonLdError(InvalidTermDefinition, "keyword aliases can't be used as prefixes")
+		}
+		definition["@prefix"] = prefix
+	}
+
+	// JSON-LD 1.1: @nest
+	if nestVal, hasNest := val["@nest"]; hasNest {
+		if !c.processingMode(1.1) {
+			return NewJsonLdError(InvalidTermDefinition, "@nest is not allowed in JSON-LD 1.0")
+		}
+		nest, isString := nestVal.(string)
+		if !isString || (IsKeyword(nest) && nest != "@nest") {
+			return NewJsonLdError(InvalidNestValue, "@nest value must be a term or @nest")
+		}
+		definition["@nest"] = nest
 	}
 
 	// 17)
@@ -412,13 +717,138 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 		}
 	}
 
+	// JSON-LD 1.1: base direction mapping
+	if directionVal, hasDirection := val["@direction"]; hasDirection && !hasType {
+		if !c.processingMode(1.1) {
+			return NewJsonLdError(InvalidTermDefinition, "@direction is not allowed in JSON-LD 1.0")
+		}
+		if directionVal != nil && !isValidDirection(directionVal) {
+			return NewJsonLdError(InvalidBaseDirection, "@direction must be either ltr, rtl or null")
+		}
+		definition["@direction"] = directionVal
+	}
+
+	// JSON-LD 1.1: property-scoped context
+	if scopedContext, hasContext := val["@context"]; hasContext {
+		if !c.processingMode(1.1) {
+			return NewJsonLdError(InvalidTermDefinition, "@context in a term definition is not allowed in JSON-LD 1.0")
+		}
+		if validateScopedContext {
+			if _, err := c.parse(scopedContext, make([]string, 0), true, true, false); err != nil {
+				return NewJsonLdError(InvalidScopedContext, err)
+			}
+		}
+		definition["@context"] = scopedContext
+	}
+
 	// 18)
-	c.termDefinitions[term] = definition
+	return c.setTermDefinition(term, definition, defined, protected, wasProtected && !overrideProtected,
+		previousDefinition)
+}
+
+// setTermDefinition completes term definition creation by storing the definition in the context.
+// If the previous definition of the term is protected and can't be overridden, the new definition
+// must be the same as the previous one.
+func (c *Context) setTermDefinition(term string, definition map[string]interface{}, defined map[string]bool,
+	protected bool, keepPrevious bool, previousDefinition interface{}) error {
+	var newDefinition interface{}
+	if definition != nil {
+		newDefinition = definition
+	}
+	if keepPrevious {
+		if !DeepCompare(previousDefinition, newDefinition, false) {
+			return NewJsonLdError(ProtectedTermRedefinition, term)
+		}
+		newDefinition = previousDefinition
+		protected = true
+	}
+	c.termDefinitions[term] = newDefinition
+	if protected {
+		c.protected[term] = true
+	}
 	defined[term] = true
 
 	return nil
 }
 
+// termDefinitionKeys lists the keys allowed in an expanded term definition in JSON-LD 1.1.
+var termDefinitionKeys = map[string]bool{
+	"@id":        true,
+	"@reverse":   true,
+	"@container": true,
+	"@context":   true,
+	"@direction": true,
+	"@index":     true,
+	"@language":  true,
+	"@nest":      true,
+	"@prefix":    true,
+	"@protected": true,
+	"@type":      true,
+}
+
+// isValidContainerMapping returns true if the given values form a valid container mapping.
+//
+// In JSON-LD 1.1, @graph may be combined with either @id or @index, and @set with
+// any other value except @list.
+func isValidContainerMapping(container []interface{}, jsonLd11 bool) bool {
+	values := make(map[string]bool, len(container))
+	for _, v := range container {
+		value, isString := v.(string)
+		if !isString || values[value] {
+			return false
+		}
+		switch value {
+		case "@list", "@set", "@index", "@language":
+		case "@graph", "@id", "@type":
+			if !jsonLd11 {
+				return false
+			}
+		default:
+			return false
+		}
+		values[value] = true
+	}
+
+	switch {
+	case len(container) == 1:
+		return true
+	case len(container) == 0 || values["@list"]:
+		return false
+	case values["@graph"]:
+		return !values["@language"] && !values["@type"] && !(values["@id"] && values["@index"])
+	default:
+		return values["@set"] && len(container) == 2
+	}
+}
+
+// keywordForm matches strings which have the form of a keyword.
+var keywordForm = regexp.MustCompile("^@[a-zA-Z]+$")
+
+// isValidDirection returns true if the given value is a valid base direction.
+func isValidDirection(value interface{}) bool {
+	return value == "ltr" || value == "rtl"
+}
+
+// isValidTypeKeywordDefinition returns true if the given value is a valid
+// JSON-LD 1.1 definition for the @type keyword.
+func isValidTypeKeywordDefinition(value map[string]interface{}) bool {
+	if len(value) == 0 {
+		return false
+	}
+	for k, v := range value {
+		switch k {
+		case "@container":
+			if v != "@set" {
+				return false
+			}
+		case "@protected":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // ExpandIri expands a string value to a full IRI.
 //
 // The string may be a term, a prefix, a relative IRI, or an absolute IRI.
@@ -431,14 +861,26 @@ func (c *Context) createTermDefinition(context map[string]interface{}, term stri
 // defined: a map for tracking cycles in context definitions (only given if called during context processing).
 func (c *Context) ExpandIri(value string, relative bool, vocab bool, context map[string]interface{},
 	defined map[string]bool) (string, error) {
+	return c.expandIri(value, relative, vocab, context, defined, false)
+}
+
+// expandIri expands a string value to a full IRI. The term definitions it depends on are
+// created with overrideProtected, as for the term definition being created.
+func (c *Context) expandIri(value string, relative bool, vocab bool, context map[string]interface{},
+	defined map[string]bool, overrideProtected bool) (string, error) {
 	// 1)
 	if IsKeyword(value) {
 		return value, nil
 	}
+	// JSON-LD 1.1: values which look like keywords are reserved for future use
+	if keywordForm.MatchString(value) {
+		c.warn("Values beginning with '@' are reserved for future use and ignored: %s", value)
+		return "", nil
+	}
 	// 2)
 	if context != nil {
 		if _, containsKey := context[value]; containsKey && !defined[value] {
-			if err := c.createTermDefinition(context, value, defined); err != nil {
+			if err := c.createTermDefinition(context, value, defined, overrideProtected, true); err != nil {
 				return "", err
 			}
 		}
@@ -465,15 +907,18 @@ func (c *Context) ExpandIri(value string, relative bool, vocab bool, context map
 		// 4.3)
 		if context != nil {
 			if _, containsPrefix := context[prefix]; containsPrefix && !defined[prefix] {
-				if err := c.createTermDefinition(context, prefix, defined); err != nil {
+				if err := c.createTermDefinition(context, prefix, defined, overrideProtected, true); err != nil {
 					return "", err
 				}
 			}
 		}
-		// 4.4)
+		// 4.4) JSON-LD 1.1: only terms with the prefix flag are used as prefixes
 		if termDef, hasPrefix := c.termDefinitions[prefix]; hasPrefix {
-			termDefMap := termDef.(map[string]interface{})
-			return termDefMap["@id"].(string) + suffix, nil
+			termDefMap, _ := termDef.(map[string]interface{})
+			if iri, hasIri := termDefMap["@id"].(string); hasIri &&
+				(!c.processingMode(1.1) || termDefMap["@prefix"] == true) {
+				return iri + suffix, nil
+			}
 		}
 		// 4.5)
 		return value, nil
@@ -531,8 +976,10 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 			// 2.4)
 			valueMap, isMap := value.(map[string]interface{})
 			_, containsIndex := valueMap["@index"]
-			if isMap && containsIndex {
-				containers = append(containers, "@index")
+			_, containsID := valueMap["@id"]
+			isGraph := IsGraph(value)
+			if isMap && containsIndex && !isGraph {
+				containers = append(containers, "@index", "@index@set")
 			}
 
 			// 2.5)
@@ -566,7 +1013,10 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 					if IsValue(item) {
 						// 2.6.4.2.1)
 						itemMap := item.(map[string]interface{})
-						if langVal, hasLang := itemMap["@language"]; hasLang {
+						if _, hasDirection := itemMap["@direction"]; hasDirection {
+							// JSON-LD 1.1
+							itemLanguage = languageDirection(itemMap)
+						} else if langVal, hasLang := itemMap["@language"]; hasLang {
 							itemLanguage = langVal.(string)
 						} else if typeVal, hasType := itemMap["@type"]; hasType {
 							// 2.6.4.2.2)
@@ -614,15 +1064,38 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 					// 2.6.8)
 					typeLanguageValue = commonLanguage
 				}
+			} else if isGraph {
+				// JSON-LD 1.1: graph objects prefer graph containers matching their @index and @id
+				if containsIndex {
+					containers = append(containers, "@graph@index", "@graph@index@set")
+				}
+				if containsID {
+					containers = append(containers, "@graph@id", "@graph@id@set")
+				}
+				containers = append(containers, "@graph", "@graph@set", "@set")
+				if !containsIndex {
+					containers = append(containers, "@graph@index", "@graph@index@set")
+				}
+				if !containsID {
+					containers = append(containers, "@graph@id", "@graph@id@set")
+				}
+				containers = append(containers, "@index", "@index@set")
+				typeLanguage = "@type"
+				typeLanguageValue = "@id"
 			} else {
 				// 2.7)
 				// 2.7.1)
 				if IsValue(value) {
 					// 2.7.1.1)
 					langVal, hasLang := valueMap["@language"]
+					_, hasDirection := valueMap["@direction"]
 					_, hasIndex := valueMap["@index"]
-					if hasLang && !hasIndex {
-						containers = append(containers, "@language")
+					if hasDirection && !hasIndex {
+						// JSON-LD 1.1
+						containers = append(containers, "@language", "@language@set")
+						typeLanguageValue = languageDirection(valueMap)
+					} else if hasLang && !hasIndex {
+						containers = append(containers, "@language", "@language@set")
 						typeLanguageValue = langVal.(string)
 					} else if typeVal, hasType := valueMap["@type"]; hasType {
 						// 2.7.1.2)
@@ -633,12 +1106,23 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 					// 2.7.2)
 					typeLanguage = "@type"
 					typeLanguageValue = "@id"
+					// JSON-LD 1.1: node objects may be values of id and type maps
+					containers = append(containers, "@id", "@id@set", "@type", "@set@type")
 				}
 				// 2.7.3)
 				containers = append(containers, "@set")
 			}
 			// 2.8)
 			containers = append(containers, "@none")
+			// JSON-LD 1.1: index and language maps may hold values without an index or a language
+			if c.processingMode(1.1) {
+				if !containsIndex {
+					containers = append(containers, "@index", "@index@set")
+				}
+				if IsValue(value) && len(valueMap) == 1 {
+					containers = append(containers, "@language", "@language@set")
+				}
+			}
 			// 2.9)
 			if typeLanguageValue == "" {
 				typeLanguageValue = "@null"
@@ -650,8 +1134,8 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 				preferredValues = append(preferredValues, "@reverse")
 			}
 			// 2.12)
-			idVal, hasID := valueMap["@id"]
-			if (typeLanguageValue == "@reverse" || typeLanguageValue == "@id") && hasID {
+			idVal := valueMap["@id"]
+			if (typeLanguageValue == "@reverse" || typeLanguageValue == "@id") && containsID {
 				// 2.12.1)
 				result := c.CompactIri(idVal.(string), nil, true, true)
 				resultVal, hasResult := c.termDefinitions[result]
@@ -673,6 +1157,14 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 				preferredValues = append(preferredValues, typeLanguageValue)
 			}
 			preferredValues = append(preferredValues, "@none")
+			// JSON-LD 1.1: fall back to terms which only match the base direction
+			if typeLanguage == "@language" {
+				for _, preferredValue := range preferredValues {
+					if i := strings.Index(preferredValue, "_"); i >= 0 {
+						preferredValues = append(preferredValues, preferredValue[i:])
+					}
+				}
+			}
 
 			// 2.14)
 			term := c.SelectTerm(iri, containers, typeLanguage, preferredValues)
@@ -713,9 +1205,11 @@ func (c *Context) CompactIri(iri string, value interface{}, relativeToVocab bool
 		}
 
 		// 5.2)
+		// JSON-LD 1.1: only terms with the prefix flag may be used as prefixes
 		termDefinition := termDefinitionVal.(map[string]interface{})
 		idStr := termDefinition["@id"].(string)
-		if iri == idStr || !strings.HasPrefix(iri, idStr) {
+		if iri == idStr || !strings.HasPrefix(iri, idStr) ||
+			(c.processingMode(1.1) && termDefinition["@prefix"] != true) {
 			continue
 		}
 
@@ -812,12 +1306,15 @@ func (c *Context) GetInverse() map[string]interface{} {
 		definition := definitionVal.(map[string]interface{})
 
 		// 3.2)
-		var container string
-		containerVal, present := definition["@container"]
-		if !present {
-			container = "@none"
-		} else {
-			container = containerVal.(string)
+		container := "@none"
+		if containerVal, present := definition["@container"]; present {
+			// JSON-LD 1.1: the key is the concatenation of the sorted container values
+			values := make([]string, 0)
+			for _, v := range containerVal.([]interface{}) {
+				values = append(values, v.(string))
+			}
+			sort.Strings(values)
+			container = strings.Join(values, "")
 		}
 
 		// 3.3)
@@ -845,6 +1342,9 @@ func (c *Context) GetInverse() map[string]interface{} {
 			typeLanguageMap = typeLanguageMapVal.(map[string]interface{})
 		}
 
+		_, hasLanguage := definition["@language"]
+		_, hasDirection := definition["@direction"]
+
 		// 3.8)
 		if reverseVal, hasValue := definition["@reverse"]; hasValue && reverseVal.(bool) {
 			typeMap := typeLanguageMap["@type"].(map[string]interface{})
@@ -858,12 +1358,9 @@ func (c *Context) GetInverse() map[string]interface{} {
 				typeMap[typeVal.(string)] = term
 			}
 			// 3.10)
-		} else if langVal, hasValue := definition["@language"]; hasValue {
+		} else if hasLanguage || hasDirection {
 			languageMap := typeLanguageMap["@language"].(map[string]interface{})
-			language := "@null"
-			if langVal != nil {
-				language = langVal.(string)
-			}
+			language := termLanguageDirection(definition)
 			if _, hasLang := languageMap[language]; !hasLang {
 				languageMap[language] = term
 			}
@@ -872,8 +1369,16 @@ func (c *Context) GetInverse() map[string]interface{} {
 			// 3.11.1)
 			languageMap := typeLanguageMap["@language"].(map[string]interface{})
 			// 3.11.2)
-			if _, hasLang := languageMap[defaultLanguage]; !hasLang {
-				languageMap[defaultLanguage] = term
+			language := defaultLanguage
+			// JSON-LD 1.1: default base direction
+			if directionVal, hasDefaultDirection := c.values["@direction"]; hasDefaultDirection {
+				language = "_" + directionVal.(string)
+				if hasLang {
+					language = defaultLanguage + language
+				}
+			}
+			if _, hasLang := languageMap[language]; !hasLang {
+				languageMap[language] = term
 			}
 			// 3.11.3)
 			if _, hasNone := languageMap["@none"]; !hasNone {
@@ -892,6 +1397,34 @@ func (c *Context) GetInverse() map[string]interface{} {
 	return c.inverse
 }
 
+// languageDirection returns the key of the inverse context language map for a value object
+// with a base direction: its language and direction separated by an underscore.
+func languageDirection(value map[string]interface{}) string {
+	language, _ := value["@language"].(string)
+	direction, _ := value["@direction"].(string)
+	return strings.ToLower(language + "_" + direction)
+}
+
+// termLanguageDirection returns the key of the inverse context language map for a term
+// with a language and/or a direction mapping.
+func termLanguageDirection(definition map[string]interface{}) string {
+	langVal, hasLanguage := definition["@language"]
+	dirVal, hasDirection := definition["@direction"]
+	language, _ := langVal.(string)
+	direction, _ := dirVal.(string)
+	switch {
+	case !hasDirection || (hasLanguage && direction == ""):
+		if langVal == nil {
+			return "@null"
+		}
+		return language
+	case !hasLanguage && direction == "":
+		return "@none"
+	default:
+		return language + "_" + direction
+	}
+}
+
 // SelectTerm picks the preferred compaction term from the inverse context entry.
 // See http://www.w3.org/TR/json-ld-api/#term-selection
 //
@@ -932,22 +1465,33 @@ func (c *Context) SelectTerm(iri string, containers []string, typeLanguage strin
 }
 
 // GetContainer retrieves container mapping for the given property.
-func (c *Context) GetContainer(property string) string {
+// In JSON-LD 1.1, a container mapping may consist of several values, such as [@graph, @index].
+func (c *Context) GetContainer(property string) []interface{} {
 	if property == "@graph" {
-		return "@set"
+		return []interface{}{"@set"}
 	}
 	if IsKeyword(property) {
-		return property
+		return []interface{}{property}
 	}
 
 	propertyMap, isMap := c.termDefinitions[property].(map[string]interface{})
 	if isMap {
 		if container, hasContainer := propertyMap["@container"]; hasContainer {
-			return container.(string)
+			return container.([]interface{})
 		}
 	}
 
-	return ""
+	return []interface{}{}
+}
+
+// HasContainerMapping returns true if the container mapping of the given property includes the given value.
+func (c *Context) HasContainerMapping(property string, value string) bool {
+	for _, container := range c.GetContainer(property) {
+		if container == value {
+			return true
+		}
+	}
+	return false
 }
 
 // IsReverseProperty returns true if the given property is a reverse property
@@ -986,6 +1530,19 @@ func (c *Context) GetLanguageMapping(property string) string {
 	return ""
 }
 
+// GetDirection returns the base direction of string values of the given property: its direction
+// mapping if the term defines one, otherwise the default base direction of this context.
+func (c *Context) GetDirection(property string) string {
+	if td := c.GetTermDefinition(property); td != nil {
+		if val, contains := td["@direction"]; contains {
+			direction, _ := val.(string)
+			return direction
+		}
+	}
+	direction, _ := c.values["@direction"].(string)
+	return direction
+}
+
 // GetTermDefinition returns a term definition for the given key
 func (c *Context) GetTermDefinition(key string) map[string]interface{} {
 	value, _ := c.termDefinitions[key].(map[string]interface{})
@@ -1033,6 +1590,10 @@ func (c *Context) ExpandValue(activeProperty string, value interface{}) (interfa
 			// 5.2)
 			rval["@language"] = langVal
 		}
+		// JSON-LD 1.1: base direction
+		if direction := c.GetDirection(activeProperty); direction != "" {
+			rval["@direction"] = direction
+		}
 	}
 	return rval, nil
 }
@@ -1048,6 +1609,9 @@ func (c *Context) Serialize() map[string]interface{} {
 	if langVal, hasLang := c.values["@language"]; hasLang {
 		ctx["@language"] = langVal
 	}
+	if directionVal, hasDirection := c.values["@direction"]; hasDirection {
+		ctx["@direction"] = directionVal
+	}
 	if vocabVal, hasVocab := c.values["@vocab"]; hasVocab {
 		ctx["@vocab"] = vocabVal
 	}
@@ -1059,7 +1623,13 @@ func (c *Context) Serialize() map[string]interface{} {
 		containerVal, hasContainer := definition["@container"]
 		typeMappingVal, hasType := definition["@type"]
 		reverseVal, hasReverse := definition["@reverse"]
-		if !hasLang && !hasContainer && !hasType && (!hasReverse || reverseVal == false) {
+		scopedContext, hasScopedContext := definition["@context"]
+		nestVal, hasNest := definition["@nest"]
+		directionVal, hasDirection := definition["@direction"]
+		indexVal, hasIndex := definition["@index"]
+		protected := c.protected[term] && definition != nil
+		if !hasLang && !hasContainer && !hasType && (!hasReverse || reverseVal == false) && !protected &&
+			!hasScopedContext && !hasNest && !hasDirection && !hasIndex {
 			var cid interface{}
 			id, hasId := definition["@id"]
 			if !hasId {
@@ -1093,7 +1663,11 @@ func (c *Context) Serialize() map[string]interface{} {
 				}
 			}
 			if hasContainer {
-				defn["@container"] = containerVal
+				if container := containerVal.([]interface{}); len(container) == 1 {
+					defn["@container"] = container[0]
+				} else {
+					defn["@container"] = container
+				}
 			}
 			if hasLang {
 				if langVal == false {
@@ -1102,6 +1676,21 @@ func (c *Context) Serialize() map[string]interface{} {
 					defn["@language"] = langVal
 				}
 			}
+			if hasDirection {
+				defn["@direction"] = directionVal
+			}
+			if hasIndex {
+				defn["@index"] = indexVal
+			}
+			if hasScopedContext {
+				defn["@context"] = scopedContext
+			}
+			if hasNest {
+				defn["@nest"] = nestVal
+			}
+			if protected {
+				defn["@protected"] = true
+			}
 			ctx[term] = defn
 		}
 	}
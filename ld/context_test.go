@@ -0,0 +1,85 @@
+package ld_test
+
+import (
+	"testing"
+
+	. "github.com/kazarena/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedContextRedefinesProtectedPrefix(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.ProcessingMode = JsonLd_1_1
+
+	// the terms depending on ex may be defined before ex itself, in any order
+	input := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"@protected": true,
+			"ex":         "http://example.org/",
+			"p": map[string]interface{}{
+				"@id": "http://example.org/p",
+				"@context": map[string]interface{}{
+					"ex": "http://example.com/",
+					"a":  "ex:a",
+					"b":  "ex:b",
+					"c":  "ex:c",
+					"d":  "ex:d",
+				},
+			},
+		},
+		"p": map[string]interface{}{"a": "value"},
+	}
+	expected := []interface{}{
+		map[string]interface{}{
+			"http://example.org/p": []interface{}{
+				map[string]interface{}{
+					"http://example.com/a": []interface{}{map[string]interface{}{"@value": "value"}},
+				},
+			},
+		},
+	}
+
+	// map iteration order is random, so expand several times
+	for i := 0; i < 200; i++ {
+		expanded, err := proc.Expand(input, opts)
+		if !assert.NoError(t, err) {
+			return
+		}
+		if !assert.Equal(t, expected, expanded) {
+			return
+		}
+	}
+}
+
+func TestKeywordLikeTermsWarning(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.ProcessingMode = JsonLd_1_1
+	warnings := make([]string, 0)
+	opts.WarningHandler = func(message string) {
+		warnings = append(warnings, message)
+	}
+
+	input := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"@foo": "http://example.org/foo",
+			"bar":  map[string]interface{}{"@id": "@bar"},
+		},
+		"@id":                  "http://example.org/a",
+		"@baz":                 "ignored",
+		"http://example.org/p": "value",
+	}
+	expanded, err := proc.Expand(input, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"@id":                  "http://example.org/a",
+			"http://example.org/p": []interface{}{map[string]interface{}{"@value": "value"}},
+		},
+	}, expanded)
+
+	assert.Contains(t, warnings, "Terms beginning with '@' are reserved for future use and ignored: @foo")
+	assert.Contains(t, warnings, "Values beginning with '@' are reserved for future use and ignored: @bar")
+	assert.Contains(t, warnings, "Values beginning with '@' are reserved for future use and ignored: @baz")
+}
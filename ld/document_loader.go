@@ -3,19 +3,27 @@ package ld
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 )
 
 const (
 	// An HTTP Accept header that prefers JSONLD.
-	acceptHeader = "application/ld+json, application/json;q=0.9, application/javascript;q=0.5, text/javascript;q=0.5, text/plain;q=0.2, */*;q=0.1"
+	acceptHeader = "application/ld+json, application/json;q=0.9, text/html;q=0.8, application/xhtml+xml;q=0.8, application/javascript;q=0.5, text/javascript;q=0.5, text/plain;q=0.2, */*;q=0.1"
 
 	// JSON-LD link header rel
 	linkHeaderRel = "http://www.w3.org/ns/json-ld#context"
+
+	// alternate link header rel
+	alternateLinkHeaderRel = "alternate"
 )
 
 // RemoteDocument is a document retrieved from a remote source.
@@ -23,6 +31,10 @@ type RemoteDocument struct {
 	DocumentURL string
 	Document    interface{}
 	ContextURL  string
+	// ContentType is the media type of the retrieved document, without parameters.
+	ContentType string
+	// Profile is the value of the profile parameter of the media type, if any.
+	Profile string
 }
 
 // DocumentLoader knows how to load remote documents.
@@ -30,6 +42,34 @@ type DocumentLoader interface {
 	LoadDocument(u string) (*RemoteDocument, error)
 }
 
+// LoadDocumentOptions are the JSON-LD 1.1 options of a document loading request.
+type LoadDocumentOptions struct {
+	// ExtractAllScripts makes the loader return all JSON-LD script elements
+	// of an HTML document as an array instead of the first one.
+	ExtractAllScripts bool
+	// RequestProfile lists the profile IRIs to request from the server
+	// in the Accept header.
+	RequestProfile []string
+}
+
+// OptionsDocumentLoader is a DocumentLoader which also accepts LoadDocumentOptions.
+type OptionsDocumentLoader interface {
+	DocumentLoader
+	LoadDocumentWithOptions(u string, opts *LoadDocumentOptions) (*RemoteDocument, error)
+}
+
+// loadDocument loads a document using the document loader from the given options,
+// passing the loading options if the document loader supports them.
+func loadDocument(u string, opts *JsonLdOptions) (*RemoteDocument, error) {
+	if loader, supportsOptions := opts.DocumentLoader.(OptionsDocumentLoader); supportsOptions {
+		return loader.LoadDocumentWithOptions(u, &LoadDocumentOptions{
+			ExtractAllScripts: opts.ExtractAllScripts,
+			RequestProfile:    opts.RequestProfile,
+		})
+	}
+	return opts.DocumentLoader.LoadDocument(u)
+}
+
 // DefaultDocumentLoader is a standard implementation of DocumentLoader
 // which can retrieve documents via HTTP.
 type DefaultDocumentLoader struct {
@@ -64,75 +104,250 @@ func DocumentFromReader(r io.Reader) (interface{}, error) {
 // LoadDocument returns a RemoteDocument containing the contents of the JSON resource
 // from the given URL.
 func (dl *DefaultDocumentLoader) LoadDocument(u string) (*RemoteDocument, error) {
+	return dl.LoadDocumentWithOptions(u, nil)
+}
+
+// LoadDocumentWithOptions returns a RemoteDocument containing the contents of the JSON resource
+// from the given URL. JSON-LD 1.1: JSON-LD script elements are extracted from HTML documents.
+// A fragment identifier in the URL selects the script element with that id.
+func (dl *DefaultDocumentLoader) LoadDocumentWithOptions(u string, opts *LoadDocumentOptions) (*RemoteDocument, error) {
+	if opts == nil {
+		opts = &LoadDocumentOptions{}
+	}
+
 	parsedURL, err := url.Parse(u)
 	if err != nil {
 		return nil, NewJsonLdError(LoadingDocumentFailed, err)
 	}
+	fragment := parsedURL.Fragment
+	if i := strings.Index(u, "#"); i >= 0 {
+		u = u[:i]
+	}
 
+	var rd *RemoteDocument
 	var documentBody io.Reader
-	var finalURL, contextURL string
 
 	protocol := parsedURL.Scheme
 	if protocol != "http" && protocol != "https" {
 		// Can't use the HTTP client for those!
-		finalURL = u
-		var file *os.File
-		file, err = os.Open(u)
+		rd = &RemoteDocument{DocumentURL: u}
+		switch strings.ToLower(filepath.Ext(u)) {
+		case ".html", ".htm":
+			rd.ContentType = "text/html"
+		case ".xhtml":
+			rd.ContentType = "application/xhtml+xml"
+		case ".jsonld":
+			rd.ContentType = "application/ld+json"
+		default:
+			rd.ContentType = "application/json"
+		}
+		file, err := os.Open(u)
 		if err != nil {
 			return nil, NewJsonLdError(LoadingDocumentFailed, err)
 		}
 		defer file.Close()
 		documentBody = file
 	} else {
-
-		req, err := http.NewRequest("GET", u, nil)
-		// We prefer application/ld+json, but fallback to application/json
-		// or whatever is available
-		req.Header.Add("Accept", acceptHeader)
-
-		res, err := dl.httpClient.Do(req)
-
+		res, err := dl.fetch(u, opts)
 		if err != nil {
-			return nil, NewJsonLdError(LoadingDocumentFailed, err)
+			return nil, err
 		}
 		defer res.Body.Close()
 
-		if res.StatusCode != http.StatusOK {
-			return nil, NewJsonLdError(LoadingDocumentFailed,
-				fmt.Sprintf("Bad response status code: %d", res.StatusCode))
-		}
+		rd = &RemoteDocument{DocumentURL: res.Request.URL.String()}
+		var params map[string]string
+		rd.ContentType, params, _ = mime.ParseMediaType(res.Header.Get("Content-Type"))
+		rd.Profile = params["profile"]
 
-		finalURL = res.Request.URL.String()
+		var links map[string][]map[string]string
+		if linkHeader := res.Header.Get("Link"); len(linkHeader) > 0 {
+			links = ParseLinkHeader(linkHeader)
+		}
 
-		contentType := res.Header.Get("Content-Type")
-		linkHeader := res.Header.Get("Link")
+		// JSON-LD 1.1: if the document isn't JSON, follow an alternate link to a JSON-LD document
+		if rd.ContentType != "application/json" && !strings.HasSuffix(rd.ContentType, "+json") {
+			for _, link := range links[alternateLinkHeaderRel] {
+				if link["type"] != "application/ld+json" {
+					continue
+				}
+				altRes, err := dl.fetch(Resolve(rd.DocumentURL, link["target"]), opts)
+				if err != nil {
+					return nil, err
+				}
+				defer altRes.Body.Close()
+
+				// the loading restarts with the alternate document, which becomes the document URL
+				res = altRes
+				rd.DocumentURL = res.Request.URL.String()
+				rd.ContentType, params, _ = mime.ParseMediaType(res.Header.Get("Content-Type"))
+				rd.Profile = params["profile"]
+				links = nil
+				if linkHeader := res.Header.Get("Link"); len(linkHeader) > 0 {
+					links = ParseLinkHeader(linkHeader)
+				}
+				break
+			}
+		}
 
-		if len(linkHeader) > 0 && contentType != "application/ld+json" {
-			header := ParseLinkHeader(linkHeader)[linkHeaderRel]
+		switch {
+		case rd.ContentType == "application/ld+json", isHTMLContentType(rd.ContentType):
+		case isJSONContentType(rd.ContentType):
+			header := links[linkHeaderRel]
 			if len(header) > 1 {
 				return nil, NewJsonLdError(MultipleContextLinkHeaders, nil)
 			} else if len(header) == 1 {
-				contextURL = header[0]["target"]
+				rd.ContextURL = Resolve(rd.DocumentURL, header[0]["target"])
 			}
+		default:
+			return nil, NewJsonLdError(LoadingDocumentFailed,
+				fmt.Sprintf("unsupported content type: %s", rd.ContentType))
 		}
 
 		documentBody = res.Body
 	}
+
+	if isHTMLContentType(rd.ContentType) {
+		rd.Document, rd.DocumentURL, err = documentFromHTML(documentBody, rd.DocumentURL, fragment,
+			opts.ExtractAllScripts)
+	} else {
+		rd.Document, err = DocumentFromReader(documentBody)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// fetch retrieves the given URL via HTTP, requesting the profiles from opts
+// in the Accept header.
+func (dl *DefaultDocumentLoader) fetch(u string, opts *LoadDocumentOptions) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
 	if err != nil {
 		return nil, NewJsonLdError(LoadingDocumentFailed, err)
 	}
-	document, err := DocumentFromReader(documentBody)
+	// We prefer application/ld+json, but fallback to application/json
+	// or whatever is available
+	accept := acceptHeader
+	if len(opts.RequestProfile) > 0 {
+		accept = fmt.Sprintf("application/ld+json;profile=\"%s\", %s",
+			strings.Join(opts.RequestProfile, " "), acceptHeader)
+	}
+	req.Header.Add("Accept", accept)
+
+	res, err := dl.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, NewJsonLdError(LoadingDocumentFailed, err)
 	}
-	return &RemoteDocument{DocumentURL: finalURL, Document: document, ContextURL: contextURL}, nil
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, NewJsonLdError(LoadingDocumentFailed,
+			fmt.Sprintf("Bad response status code: %d", res.StatusCode))
+	}
+	return res, nil
 }
 
-var rSplitOnComma = regexp.MustCompile("(?:<[^>]*?>|\"[^\"]*?\"|[^,])+")
-var rLinkHeader = regexp.MustCompile("\\s*<([^>]*?)>\\s*(?:;\\s*(.*))?")
-var rParams = regexp.MustCompile("(.*?)=(?:(?:\"([^\"]*?)\")|([^\"]*?))\\s*(?:(?:;\\s*)|$)")
+// isHTMLContentType returns true if the given media type is an HTML media type.
+func isHTMLContentType(contentType string) bool {
+	return contentType == "text/html" || contentType == "application/xhtml+xml"
+}
 
-// ParseLinkHeader parses a link header. The results will be keyed by the value of "rel".
+// isJSONContentType returns true if a document of the given media type is parsed as JSON.
+// Besides JSON types, this includes the generic types requested in the Accept header,
+// which are often used to serve JSON documents.
+func isJSONContentType(contentType string) bool {
+	switch contentType {
+	case "", "application/json", "application/javascript", "text/javascript", "text/plain",
+		"application/octet-stream":
+		return true
+	}
+	return strings.HasSuffix(contentType, "+json")
+}
+
+var rHTMLScript = regexp.MustCompile(`(?is)<!--.*?-->|<script\b([^>]*)>(.*?)</script\s*>`)
+var rHTMLBase = regexp.MustCompile(`(?is)<!--.*?-->|<base\b([^>]*)>`)
+var rHTMLAttribute = regexp.MustCompile(`([^\s"'=/>]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>]+)))?`)
+
+// htmlAttributes parses the attributes of an HTML start tag.
+func htmlAttributes(attrs string) map[string]string {
+	rval := make(map[string]string)
+	for _, match := range rHTMLAttribute.FindAllStringSubmatch(attrs, -1) {
+		name := strings.ToLower(match[1])
+		if _, present := rval[name]; !present {
+			rval[name] = html.UnescapeString(match[2] + match[3] + match[4])
+		}
+	}
+	return rval
+}
+
+// documentFromHTML extracts JSON-LD from the script elements of the HTML document
+// read from r, as described in https://www.w3.org/TR/json-ld11-api/#process-html.
+// It returns the document and the URL which should be used as its base IRI,
+// taking the base element into account.
+func documentFromHTML(r io.Reader, documentURL string, fragment string,
+	extractAllScripts bool) (interface{}, string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", NewJsonLdError(LoadingDocumentFailed, err)
+	}
+
+	// the first base element sets the base IRI of the document
+	for _, match := range rHTMLBase.FindAllSubmatch(content, -1) {
+		if match[1] == nil {
+			// a comment
+			continue
+		}
+		if href, hasHref := htmlAttributes(string(match[1]))["href"]; hasHref {
+			documentURL = Resolve(documentURL, href)
+			break
+		}
+	}
+
+	documents := make([]interface{}, 0)
+	for _, match := range rHTMLScript.FindAllSubmatch(content, -1) {
+		if match[1] == nil {
+			// a comment
+			continue
+		}
+		attrs := htmlAttributes(string(match[1]))
+		if fragment != "" && attrs["id"] != fragment {
+			continue
+		}
+		mediaType, _, _ := mime.ParseMediaType(attrs["type"])
+		if mediaType != "application/ld+json" {
+			if fragment != "" {
+				return nil, "", NewJsonLdError(LoadingDocumentFailed,
+					fmt.Sprintf("script element with id %s is not a JSON-LD script", fragment))
+			}
+			continue
+		}
+
+		var document interface{}
+		if err := json.Unmarshal(match[2], &document); err != nil {
+			return nil, "", NewJsonLdError(InvalidScriptElement, err)
+		}
+		if fragment != "" || !extractAllScripts {
+			return document, documentURL, nil
+		}
+		if documentList, isList := document.([]interface{}); isList {
+			documents = append(documents, documentList...)
+		} else {
+			documents = append(documents, document)
+		}
+	}
+
+	if fragment != "" {
+		return nil, "", NewJsonLdError(LoadingDocumentFailed,
+			fmt.Sprintf("no script element with id %s", fragment))
+	}
+	if !extractAllScripts {
+		return nil, "", NewJsonLdError(LoadingDocumentFailed, "no JSON-LD script element found")
+	}
+	return documents, documentURL, nil
+}
+
+// ParseLinkHeader parses a link header as defined in RFC 8288.
+// The results will be keyed by the value of "rel".
 //
 // Link: <http://json-ld.org/contexts/person.jsonld>; \
 //   rel="http://www.w3.org/ns/json-ld#context"; type="application/ld+json"
@@ -145,46 +360,155 @@ var rParams = regexp.MustCompile("(.*?)=(?:(?:\"([^\"]*?)\")|([^\"]*?))\\s*(?:(?
 // }
 //
 // If there is more than one "rel" with the same IRI, then entries in the
-// resulting map for that "rel" will be lists.
+// resulting map for that "rel" will be lists. A link with several relation types
+// (such as rel="alternate meta") is listed under each of them. Parameter names and
+// registered relation types are lower-cased, only the first occurrence of a parameter
+// is kept, and extended parameter values (such as title*=UTF-8''%e2%82%ac) are decoded.
 func ParseLinkHeader(header string) map[string][]map[string]string {
 
 	rval := make(map[string][]map[string]string)
 
-	// split on unbracketed/unquoted commas
-	entries := rSplitOnComma.FindAllString(header, -1)
-	if len(entries) == 0 {
-		return rval
-	}
-
-	for _, entry := range entries {
-		if !rLinkHeader.MatchString(entry) {
+	p := &linkHeaderParser{header: header}
+	for {
+		p.skip(", \t")
+		if p.pos >= len(p.header) {
+			break
+		}
+		if p.header[p.pos] != '<' {
+			// not a link-value: skip to the next one
+			p.skipTo(',')
 			continue
 		}
-		match := rLinkHeader.FindStringSubmatch(entry)
-
+		end := strings.IndexByte(p.header[p.pos:], '>')
+		if end < 0 {
+			break
+		}
 		result := map[string]string{
-			"target": match[1],
-		}
-		params := match[2]
-		matches := rParams.FindAllStringSubmatch(params, -1)
-		for _, match := range matches {
-			if match[2] == "" {
-				result[match[1]] = match[3]
-			} else {
-				result[match[1]] = match[2]
+			"target": p.header[p.pos+1 : p.pos+end],
+		}
+		p.pos += end + 1
+
+		for {
+			p.skip(" \t")
+			if p.pos >= len(p.header) || p.header[p.pos] != ';' {
+				break
+			}
+			p.pos++
+			p.skip(" \t")
+			name := strings.ToLower(p.token())
+			p.skip(" \t")
+			value := ""
+			if p.pos < len(p.header) && p.header[p.pos] == '=' {
+				p.pos++
+				p.skip(" \t")
+				if p.pos < len(p.header) && p.header[p.pos] == '"' {
+					value = p.quotedString()
+				} else {
+					value = p.token()
+				}
+			}
+			if name == "" {
+				continue
+			}
+			if strings.HasSuffix(name, "*") {
+				name = name[:len(name)-1]
+				value = decodeExtValue(value)
+			}
+			if _, present := result[name]; !present {
+				result[name] = value
 			}
 		}
-		rel := result["rel"]
-		relVal, hasRel := rval[rel]
-		if hasRel {
-			rval[rel] = append(relVal, result)
-		} else {
-			rval[rel] = []map[string]string{result}
+		p.skipTo(',')
+
+		rels := strings.Fields(result["rel"])
+		if len(rels) == 0 {
+			rels = []string{""}
+		}
+		for _, rel := range rels {
+			if !strings.Contains(rel, ":") {
+				// registered relation types are case-insensitive
+				rel = strings.ToLower(rel)
+			}
+			link := result
+			if rel != result["rel"] {
+				link = make(map[string]string, len(result))
+				for k, v := range result {
+					link[k] = v
+				}
+				link["rel"] = rel
+			}
+			rval[rel] = append(rval[rel], link)
 		}
 	}
 	return rval
 }
 
+// linkHeaderParser is a simple scanner over the value of a Link header.
+type linkHeaderParser struct {
+	header string
+	pos    int
+}
+
+// skip advances past any of the given characters.
+func (p *linkHeaderParser) skip(chars string) {
+	for p.pos < len(p.header) && strings.IndexByte(chars, p.header[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// skipTo advances to the next occurrence of c outside of a quoted string.
+func (p *linkHeaderParser) skipTo(c byte) {
+	for p.pos < len(p.header) && p.header[p.pos] != c {
+		if p.header[p.pos] == '"' {
+			p.quotedString()
+		} else {
+			p.pos++
+		}
+	}
+}
+
+// token reads a parameter name or an unquoted parameter value.
+func (p *linkHeaderParser) token() string {
+	start := p.pos
+	for p.pos < len(p.header) && strings.IndexByte(",;= \t\"", p.header[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.header[start:p.pos]
+}
+
+// quotedString reads a quoted string, unescaping quoted pairs.
+func (p *linkHeaderParser) quotedString() string {
+	buf := make([]byte, 0)
+	p.pos++
+	for p.pos < len(p.header) {
+		c := p.header[p.pos]
+		p.pos++
+		if c == '"' {
+			break
+		}
+		if c == '\\' && p.pos < len(p.header) {
+			c = p.header[p.pos]
+			p.pos++
+		}
+		buf = append(buf, c)
+	}
+	return string(buf)
+}
+
+// decodeExtValue decodes an extended parameter value as defined in RFC 8187,
+// such as UTF-8'en'%e2%82%ac. Values which can't be decoded are returned unchanged.
+func decodeExtValue(value string) string {
+	parts := strings.SplitN(value, "'", 3)
+	if len(parts) != 3 || !strings.EqualFold(parts[0], "utf-8") {
+		return value
+	}
+	decoded, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
 // CachingDocumentLoader is an overlay on top of DocumentLoader instance
 // which allows caching documents as soon as they get retrieved
 // from the underlying loader. You may also preload it with documents -
@@ -219,9 +543,21 @@ func (cdl *CachingDocumentLoader) LoadDocument(u string) (*RemoteDocument, error
 	}
 }
 
+// LoadDocumentWithOptions returns a RemoteDocument containing the contents of the JSON resource
+// from the given URL. Only documents loaded with default options are cached.
+func (cdl *CachingDocumentLoader) LoadDocumentWithOptions(u string, opts *LoadDocumentOptions) (*RemoteDocument, error) {
+	if opts == nil || (!opts.ExtractAllScripts && len(opts.RequestProfile) == 0) {
+		return cdl.LoadDocument(u)
+	}
+	if optsLoader, supportsOptions := cdl.nextLoader.(OptionsDocumentLoader); supportsOptions {
+		return optsLoader.LoadDocumentWithOptions(u, opts)
+	}
+	return cdl.nextLoader.LoadDocument(u)
+}
+
 // AddDocument populates the cache with the given document (doc) for the provided URL (u).
 func (cdl *CachingDocumentLoader) AddDocument(u string, doc interface{}) {
-	cdl.cache[u] = &RemoteDocument{DocumentURL: u, Document: doc, ContextURL: ""}
+	cdl.cache[u] = &RemoteDocument{DocumentURL: u, Document: doc, ContextURL: "", ContentType: "application/ld+json"}
 }
 
 // PreloadWithMapping populates the cache with a number of documents which may be loaded
@@ -5,6 +5,9 @@ import (
 	. "github.com/kazarena/json-gold/ld"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +19,23 @@ func TestLoadDocument(t *testing.T) {
 	assert.Equal(t, "t1", rd.Document.(map[string]interface{})["@type"])
 }
 
+func TestLoadDocumentFromHTML(t *testing.T) {
+	dl := NewDefaultDocumentLoader(nil)
+
+	rd, err := dl.LoadDocument("testdata/remote-doc-th03-in.html#second")
+	require.Nil(t, err)
+	assert.Equal(t, "http://example.org/second", rd.Document.([]interface{})[0].(map[string]interface{})["@id"])
+
+	rd, err = dl.LoadDocumentWithOptions("testdata/remote-doc-th02-in.html",
+		&LoadDocumentOptions{ExtractAllScripts: true})
+	require.Nil(t, err)
+	assert.Len(t, rd.Document, 2)
+
+	rd, err = dl.LoadDocument("testdata/remote-doc-th04-in.html")
+	require.Nil(t, err)
+	assert.Equal(t, "http://example.org/documents/", rd.DocumentURL)
+}
+
 func loadBenchData(t testing.TB) *RDFDataset {
 	dl := NewDefaultDocumentLoader(nil)
 	rd, err := dl.LoadDocument("testdata/compact-manifest.jsonld")
@@ -54,6 +74,80 @@ func TestParseLinkHeader(t *testing.T) {
 	)
 }
 
+func TestParseLinkHeaderRFC8288(t *testing.T) {
+	rval := ParseLinkHeader("<alternate.jsonld>; REL=\"Alternate meta\"; type=application/ld+json; " +
+		"title=\"a, \\\"quoted\\\" title\"; title=ignored, " +
+		"<euro.jsonld>; rel=http://example.org/rel; title*=UTF-8'en'%e2%82%ac%20rates")
+
+	assert.Equal(
+		t,
+		map[string][]map[string]string{
+			"alternate": {{
+				"target": "alternate.jsonld",
+				"rel":    "alternate",
+				"type":   "application/ld+json",
+				"title":  "a, \"quoted\" title",
+			}},
+			"meta": {{
+				"target": "alternate.jsonld",
+				"rel":    "meta",
+				"type":   "application/ld+json",
+				"title":  "a, \"quoted\" title",
+			}},
+			"http://example.org/rel": {{
+				"target": "euro.jsonld",
+				"rel":    "http://example.org/rel",
+				"title":  "\u20ac rates",
+			}},
+		},
+		rval,
+	)
+}
+
+func TestLoadDocumentWithRequestProfile(t *testing.T) {
+	var accept string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/ld+json; profile=\"http://www.w3.org/ns/json-ld#expanded\"")
+		w.Write([]byte(`[{"@id": "http://example.org/doc"}]`))
+	}))
+	defer ts.Close()
+
+	dl := NewDefaultDocumentLoader(nil)
+	rd, err := dl.LoadDocumentWithOptions(ts.URL+"/doc", &LoadDocumentOptions{
+		RequestProfile: []string{"http://www.w3.org/ns/json-ld#expanded"},
+	})
+	require.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(accept, "application/ld+json;profile=\"http://www.w3.org/ns/json-ld#expanded\", "))
+	assert.Equal(t, "application/ld+json", rd.ContentType)
+	assert.Equal(t, "http://www.w3.org/ns/json-ld#expanded", rd.Profile)
+}
+
+func TestLoadDocumentContentTypes(t *testing.T) {
+	var contentType string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(`{"@id": "http://example.org/doc"}`))
+	}))
+	defer ts.Close()
+
+	dl := NewDefaultDocumentLoader(nil)
+	for _, contentType = range []string{
+		"application/json", "application/activity+json", "text/plain; charset=utf-8",
+		"application/javascript", "text/javascript", "application/octet-stream",
+	} {
+		rd, err := dl.LoadDocument(ts.URL + "/doc")
+		require.Nil(t, err, contentType)
+		assert.Equal(t, map[string]interface{}{"@id": "http://example.org/doc"}, rd.Document, contentType)
+	}
+
+	contentType = "image/png"
+	_, err := dl.LoadDocument(ts.URL + "/doc")
+	require.NotNil(t, err)
+	assert.Equal(t, LoadingDocumentFailed, err.(*JsonLdError).Code)
+}
+
 func TestCachingDocumentLoaderLoadDocument(t *testing.T) {
 	cl := NewCachingDocumentLoader(NewDefaultDocumentLoader(nil))
 
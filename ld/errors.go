@@ -50,6 +50,22 @@ const (
 	InvalidReversePropertyMap   ErrorCode = "invalid reverse property map"
 	InvalidReverseValue         ErrorCode = "invalid @reverse value"
 	InvalidReversePropertyValue ErrorCode = "invalid reverse property value"
+	ProcessingModeConflict      ErrorCode = "processing mode conflict"
+	InvalidVersionValue         ErrorCode = "invalid @version value"
+	InvalidProtectedValue       ErrorCode = "invalid @protected value"
+	ProtectedTermRedefinition   ErrorCode = "protected term redefinition"
+	InvalidContextNullification ErrorCode = "invalid context nullification"
+	InvalidScopedContext        ErrorCode = "invalid scoped context"
+	InvalidPropagateValue       ErrorCode = "invalid @propagate value"
+	InvalidContextEntry         ErrorCode = "invalid context entry"
+	InvalidNestValue            ErrorCode = "invalid @nest value"
+	InvalidImportValue          ErrorCode = "invalid @import value"
+	InvalidIncludedValue        ErrorCode = "invalid @included value"
+	InvalidJSONLiteral          ErrorCode = "invalid JSON literal"
+	InvalidBaseDirection        ErrorCode = "invalid base direction"
+	InvalidPrefixValue          ErrorCode = "invalid @prefix value"
+	InvalidEmbedValue           ErrorCode = "invalid @embed value"
+	InvalidScriptElement        ErrorCode = "invalid script element"
 
 	// non spec related errors
 	SyntaxError    ErrorCode = "syntax error"
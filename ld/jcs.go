@@ -0,0 +1,179 @@
+package ld
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CanonicalizeJSON serializes the given JSON value according to the JSON Canonicalization
+// Scheme (JCS), see https://tools.ietf.org/html/rfc8785
+//
+// It is used to produce the lexical form of rdf:JSON literals.
+func CanonicalizeJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, value); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func writeCanonicalJSON(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(buf, v)
+	case float64:
+		number, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case int:
+		buf.WriteString(strconv.Itoa(v))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return NewJsonLdError(InvalidJSONLiteral, fmt.Sprintf("invalid number: %s", v))
+		}
+		number, err := canonicalNumber(f)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		// keys are sorted by their UTF-16 code units
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return lessUTF16(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonicalJSON(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return NewJsonLdError(InvalidJSONLiteral, fmt.Sprintf("unsupported JSON value: %v", value))
+	}
+	return nil
+}
+
+// writeCanonicalString writes a JSON string escaping only the characters JCS requires to be escaped.
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString("\\\"")
+		case '\\':
+			buf.WriteString("\\\\")
+		case '\b':
+			buf.WriteString("\\b")
+		case '\f':
+			buf.WriteString("\\f")
+		case '\n':
+			buf.WriteString("\\n")
+		case '\r':
+			buf.WriteString("\\r")
+		case '\t':
+			buf.WriteString("\\t")
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, "\\u%04x", r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// canonicalNumber serializes a number the way ECMAScript's Number.prototype.toString does.
+func canonicalNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", NewJsonLdError(InvalidJSONLiteral, "NaN and Infinity are not valid JSON numbers")
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// shortest representation which round trips, in the form d.ddde±xx
+	exponential := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exponent := exponential, 0
+	if i := strings.IndexByte(exponential, 'e'); i >= 0 {
+		mantissa = exponential[:i]
+		exponent, _ = strconv.Atoi(exponential[i+1:])
+	}
+	digits := strings.Replace(mantissa, ".", "", 1)
+	k := len(digits)
+	n := exponent + 1
+
+	var result string
+	switch {
+	case k <= n && n <= 21:
+		result = digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		result = digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		result = "0." + strings.Repeat("0", -n) + digits
+	default:
+		exponentSign := "+"
+		if n-1 < 0 {
+			exponentSign = "-"
+		}
+		result = digits[:1]
+		if k > 1 {
+			result += "." + digits[1:]
+		}
+		result += "e" + exponentSign + strconv.Itoa(int(math.Abs(float64(n-1))))
+	}
+	return sign + result, nil
+}
+
+// lessUTF16 compares two strings by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
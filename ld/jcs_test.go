@@ -0,0 +1,73 @@
+package ld_test
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/kazarena/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalizeJSON(t *testing.T) {
+	result, err := CanonicalizeJSON(map[string]interface{}{
+		"z":  true,
+		"a":  []interface{}{1.0, 1e21, 0.000001, 1e-7, -0.5, nil},
+		"€":  "euro",
+		"\r": "cr",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"\r":"cr","a":[1,1e+21,0.000001,1e-7,-0.5,null],"z":true,"€":"euro"}`, result)
+
+	result, err = CanonicalizeJSON("line\nbreak \"quoted\" \u0001 é")
+	assert.NoError(t, err)
+	assert.Equal(t, `"line\nbreak \"quoted\" \u0001 é"`, result)
+
+	_, err = CanonicalizeJSON(math.NaN())
+	assert.Error(t, err)
+}
+
+func TestToRDFInvalidJSONLiteral(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.ProcessingMode = JsonLd_1_1
+
+	doc := map[string]interface{}{
+		"@id": "http://example.org/a",
+		"http://example.org/p": map[string]interface{}{
+			"@value": math.Inf(1),
+			"@type":  "@json",
+		},
+	}
+	_, err := proc.ToRDF(doc, opts)
+	if assert.Error(t, err) {
+		assert.Equal(t, InvalidJSONLiteral, err.(*JsonLdError).Code)
+	}
+}
+
+func TestFromRDFJSONLiteralProcessingMode(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	nquads := `<http://example.org/a> <http://example.org/p> "{\"a\":1}"^^<http://www.w3.org/1999/02/22-rdf-syntax-ns#JSON> .` + "\n"
+
+	// JSON-LD 1.0 rules apply unless the processing mode is json-ld-1.1
+	opts := NewJsonLdOptions("")
+	doc, err := proc.FromRDF(nquads, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"@id": "http://example.org/a",
+		"http://example.org/p": []interface{}{map[string]interface{}{
+			"@value": `{"a":1}`,
+			"@type":  "http://www.w3.org/1999/02/22-rdf-syntax-ns#JSON",
+		}},
+	}}, doc)
+
+	opts.ProcessingMode = JsonLd_1_1
+	doc, err = proc.FromRDF(nquads, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"@id": "http://example.org/a",
+		"http://example.org/p": []interface{}{map[string]interface{}{
+			"@value": map[string]interface{}{"a": 1.0},
+			"@type":  "@json",
+		}},
+	}}, doc)
+}
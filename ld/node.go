@@ -147,7 +147,11 @@ var patternInteger = regexp.MustCompile("^[\\-+]?[0-9]+$")
 var patternDouble = regexp.MustCompile("^(\\+|-)?([0-9]+(\\.[0-9]*)?|\\.[0-9]+)([Ee](\\+|-)?[0-9]+)?$")
 
 // rdfToObject converts an RDF triple object to a JSON-LD object.
-func rdfToObject(n Node, useNativeTypes bool) (map[string]interface{}, error) {
+// In json-ld-1.1 processing mode, rdf:JSON literals are converted to JSON literals.
+// If rdfDirection is i18n-datatype, literals with an i18n datatype are converted to strings
+// with a base direction.
+func rdfToObject(n Node, useNativeTypes bool, processingMode string, rdfDirection string) (map[string]interface{},
+	error) {
 	// If value is an an IRI or a blank node identifier, return a new
 	// JSON object consisting
 	// of a single member @id whose value is set to value.
@@ -159,11 +163,35 @@ func rdfToObject(n Node, useNativeTypes bool) (map[string]interface{}, error) {
 
 	literal := n.(*Literal)
 
+	// JSON-LD 1.1: JSON literals
+	if literal.Datatype == RDFJSON && isProcessingMode(processingMode, 1.1) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(literal.Value), &value); err != nil {
+			return nil, NewJsonLdError(InvalidJSONLiteral, literal.Value)
+		}
+		return map[string]interface{}{
+			"@value": value,
+			"@type":  "@json",
+		}, nil
+	}
+
 	// convert literal object to JSON-LD
 	rval := map[string]interface{}{
 		"@value": literal.GetValue(),
 	}
 
+	// JSON-LD 1.1: strings with a base direction
+	if rdfDirection == RdfDirectionI18nDatatype && strings.HasPrefix(literal.Datatype, I18NNS) {
+		languageDirection := literal.Datatype[len(I18NNS):]
+		if i := strings.LastIndex(languageDirection, "_"); i >= 0 {
+			if i > 0 {
+				rval["@language"] = languageDirection[:i]
+			}
+			rval["@direction"] = languageDirection[i+1:]
+			return rval, nil
+		}
+	}
+
 	// add language
 	if literal.Language != "" {
 		rval["@language"] = literal.Language
@@ -214,13 +242,27 @@ func rdfToObject(n Node, useNativeTypes bool) (map[string]interface{}, error) {
 
 // objectToRDF converts a JSON-LD value object to an RDF literal or a JSON-LD string or
 // node object to an RDF resource.
-func objectToRDF(item interface{}) Node {
+//
+// The base direction of strings is represented according to rdfDirection. In compound-literal mode
+// the returned blank node is described by additional triples which are returned together with it.
+// An error is returned if a JSON literal can't be serialized as canonical JSON.
+func objectToRDF(item interface{}, issuer *IdentifierIssuer, graphName string, rdfDirection string) (Node, []*Quad,
+	error) {
 	// convert value object to RDF
 	if IsValue(item) {
 		itemMap := item.(map[string]interface{})
 		value := itemMap["@value"]
 		datatype := itemMap["@type"]
 
+		// JSON-LD 1.1: the lexical form of a JSON literal is its canonical JSON serialization
+		if datatype == "@json" {
+			canonicalJSON, err := CanonicalizeJSON(value)
+			if err != nil {
+				return nil, nil, err
+			}
+			return NewLiteral(canonicalJSON, RDFJSON, ""), nil, nil
+		}
+
 		// convert to XSD datatypes as appropriate
 		booleanVal, isBool := value.(bool)
 		floatVal, isFloat := value.(float64)
@@ -248,35 +290,54 @@ func objectToRDF(item interface{}) Node {
 			// convert to XSD datatype
 			if isBool {
 				if datatype == nil {
-					return NewLiteral(strconv.FormatBool(booleanVal), XSDBoolean, "")
+					return NewLiteral(strconv.FormatBool(booleanVal), XSDBoolean, ""), nil, nil
 				} else {
-					return NewLiteral(strconv.FormatBool(booleanVal), datatypeStr, "")
+					return NewLiteral(strconv.FormatBool(booleanVal), datatypeStr, ""), nil, nil
 				}
 			} else if (isFloat && !isInteger) || XSDDouble == datatypeStr {
 				canonicalDouble := GetCanonicalDouble(floatVal)
 				if datatype == nil {
-					return NewLiteral(canonicalDouble, XSDDouble, "")
+					return NewLiteral(canonicalDouble, XSDDouble, ""), nil, nil
 				} else {
-					return NewLiteral(canonicalDouble, datatypeStr, "")
+					return NewLiteral(canonicalDouble, datatypeStr, ""), nil, nil
 				}
 			} else {
 				if datatype == nil {
-					return NewLiteral(fmt.Sprintf("%d", int(floatVal)), XSDInteger, "")
+					return NewLiteral(fmt.Sprintf("%d", int(floatVal)), XSDInteger, ""), nil, nil
 				} else {
-					return NewLiteral(fmt.Sprintf("%d", int(floatVal)), datatype.(string), "")
+					return NewLiteral(fmt.Sprintf("%d", int(floatVal)), datatype.(string), ""), nil, nil
 				}
 			}
+		} else if directionVal, hasDirection := itemMap["@direction"]; hasDirection && rdfDirection != "" {
+			// JSON-LD 1.1: strings with a base direction
+			language, _ := itemMap["@language"].(string)
+			language = strings.ToLower(language)
+			direction := directionVal.(string)
+			if rdfDirection == RdfDirectionI18nDatatype {
+				return NewLiteral(value.(string), I18NNS+language+"_"+direction, ""), nil, nil
+			}
+			literal := NewBlankNode(issuer.GetId(""))
+			triples := []*Quad{
+				NewQuad(literal, NewIRI(RDFValue), NewLiteral(value.(string), XSDString, ""), graphName),
+			}
+			if language != "" {
+				triples = append(triples, NewQuad(literal, NewIRI(RDFLanguage), NewLiteral(language, XSDString, ""),
+					graphName))
+			}
+			triples = append(triples, NewQuad(literal, NewIRI(RDFDirection), NewLiteral(direction, XSDString, ""),
+				graphName))
+			return literal, triples, nil
 		} else if langVal, hasLang := itemMap["@language"]; hasLang {
 			if datatype == nil {
-				return NewLiteral(value.(string), RDFLangString, langVal.(string))
+				return NewLiteral(value.(string), RDFLangString, langVal.(string)), nil, nil
 			} else {
-				return NewLiteral(value.(string), datatype.(string), langVal.(string))
+				return NewLiteral(value.(string), datatype.(string), langVal.(string)), nil, nil
 			}
 		} else {
 			if datatype == nil {
-				return NewLiteral(value.(string), XSDString, "")
+				return NewLiteral(value.(string), XSDString, ""), nil, nil
 			} else {
-				return NewLiteral(value.(string), datatype.(string), "")
+				return NewLiteral(value.(string), datatype.(string), ""), nil, nil
 			}
 		}
 	} else {
@@ -285,16 +346,16 @@ func objectToRDF(item interface{}) Node {
 		if itemMap, isMap := item.(map[string]interface{}); isMap {
 			id = itemMap["@id"].(string)
 			if IsRelativeIri(id) {
-				return nil
+				return nil, nil, nil
 			}
 		} else {
 			id = item.(string)
 		}
 		if strings.Index(id, "_:") == 0 {
 			// NOTE: once again no need to rename existing blank nodes
-			return NewBlankNode(id)
+			return NewBlankNode(id), nil, nil
 		} else {
-			return NewIRI(id)
+			return NewIRI(id), nil, nil
 		}
 	}
 }
@@ -6,6 +6,21 @@ const (
 	JsonLd_1_1_Frame = "json-ld-1.1-expand-frame"
 )
 
+// isProcessingMode returns true if the processing mode is the given version (1.0 or 1.1)
+// or later. An empty processing mode follows JSON-LD 1.0 rules.
+func isProcessingMode(mode string, version float64) bool {
+	if version >= 1.1 {
+		return mode >= JsonLd_1_1
+	}
+	return mode == "" || mode >= JsonLd_1_0
+}
+
+// Values of the RdfDirection option
+const (
+	RdfDirectionI18nDatatype    = "i18n-datatype"
+	RdfDirectionCompoundLiteral = "compound-literal"
+)
+
 // JsonLdOptions type as specified in the JSON-LD-API specification:
 // http://www.w3.org/TR/json-ld-api/#the-jsonldoptions-type
 type JsonLdOptions struct {
@@ -19,21 +34,58 @@ type JsonLdOptions struct {
 	// http://www.w3.org/TR/json-ld-api/#widl-JsonLdOptions-expandContext
 	ExpandContext interface{}
 	// http://www.w3.org/TR/json-ld-api/#widl-JsonLdOptions-processingMode
+	// If empty, the processor follows JSON-LD 1.0 rules until a context
+	// declares "@version": 1.1.
 	ProcessingMode string
 	// http://www.w3.org/TR/json-ld-api/#widl-JsonLdOptions-documentLoader
 	DocumentLoader DocumentLoader
+	// https://www.w3.org/TR/json-ld11-api/#dom-jsonldoptions-extractallscripts
+	// If true, all JSON-LD script elements of an HTML input document are extracted
+	// into an array. Otherwise, only the first one is used. Requires a DocumentLoader
+	// which implements OptionsDocumentLoader.
+	ExtractAllScripts bool
+	// RequestProfile lists the profile IRIs which are requested from remote servers
+	// through the Accept header when loading input documents. Requires a DocumentLoader
+	// which implements OptionsDocumentLoader.
+	RequestProfile []string
+	// WarningHandler is called with a message when the processor ignores a part of
+	// a document, such as a term having the form of a keyword. Warnings are
+	// discarded if it is nil.
+	WarningHandler func(message string)
 
 	// Frame options: http://json-ld.org/spec/latest/json-ld-framing/
 
-	Embed       bool
+	// https://www.w3.org/TR/json-ld11-framing/#dom-jsonldoptions-embed
+	// The default @embed flag: @always, @once, @never or @link (@last is accepted
+	// for JSON-LD 1.0 framing). If empty, @once is used in json-ld-1.1 processing mode
+	// and @last otherwise.
+	Embed       Embed
 	Explicit    bool
 	OmitDefault bool
+	// https://www.w3.org/TR/json-ld11-framing/#dom-jsonldoptions-requireall
+	// If true, a node only matches a frame if all of the frame's properties match.
+	RequireAll bool
+	// https://www.w3.org/TR/json-ld11-framing/#dom-jsonldoptions-omitgraph
+	// If true, a single framed result is returned as a top-level object
+	// instead of being wrapped in @graph.
+	OmitGraph bool
+	// If true, blank node identifiers which are used only once are removed from
+	// the framed output. This is always done in json-ld-1.1 processing mode.
+	PruneBlankNodeIdentifiers bool
+	// https://www.w3.org/TR/json-ld11-framing/#dom-jsonldoptions-framedefault
+	// If true, only the default graph is framed. Otherwise, the node objects of
+	// all graphs are merged before framing.
+	FrameDefault bool
 
 	// RDF conversion options: http://www.w3.org/TR/json-ld-api/#serialize-rdf-as-json-ld-algorithm
 
 	UseRdfType            bool
 	UseNativeTypes        bool
 	ProduceGeneralizedRdf bool
+	// https://www.w3.org/TR/json-ld11-api/#dom-jsonldoptions-rdfdirection
+	// Determines how the base direction of strings is represented in RDF:
+	// either i18n-datatype or compound-literal. If empty, the direction is dropped.
+	RdfDirection string
 
 	// The following properties aren't in the spec
 
@@ -47,20 +99,28 @@ type JsonLdOptions struct {
 // NewJsonLdOptions creates and returns new instance of JsonLdOptions with the given base.
 func NewJsonLdOptions(base string) *JsonLdOptions {
 	return &JsonLdOptions{
-		Base:                  base,
-		CompactArrays:         true,
-		ProcessingMode:        JsonLd_1_0,
-		DocumentLoader:        NewDefaultDocumentLoader(nil),
-		Embed:                 true,
-		Explicit:              false,
-		OmitDefault:           false,
-		UseRdfType:            false,
-		UseNativeTypes:        false,
-		ProduceGeneralizedRdf: false,
-		InputFormat:           "",
-		Format:                "",
-		Algorithm:             "URGNA2012",
-		UseNamespaces:         false,
-		OutputForm:            "",
+		Base:                      base,
+		CompactArrays:             true,
+		ProcessingMode:            "",
+		DocumentLoader:            NewDefaultDocumentLoader(nil),
+		ExtractAllScripts:         false,
+		RequestProfile:            nil,
+		WarningHandler:            nil,
+		Embed:                     "",
+		Explicit:                  false,
+		OmitDefault:               false,
+		RequireAll:                false,
+		OmitGraph:                 false,
+		PruneBlankNodeIdentifiers: false,
+		FrameDefault:              false,
+		UseRdfType:                false,
+		UseNativeTypes:            false,
+		ProduceGeneralizedRdf:     false,
+		RdfDirection:              "",
+		InputFormat:               "",
+		Format:                    "",
+		Algorithm:                 "URGNA2012",
+		UseNamespaces:             false,
+		OutputForm:                "",
 	}
 }
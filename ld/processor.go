@@ -99,7 +99,7 @@ func (jldp *JsonLdProcessor) expand(input interface{}, opts *JsonLdOptions) ([]i
 
 	// 2)
 	if iri, isString := input.(string); isString && strings.Contains(iri, ":") {
-		rd, err := opts.DocumentLoader.LoadDocument(iri)
+		rd, err := loadDocument(iri, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -317,32 +317,56 @@ func (jldp *JsonLdProcessor) Frame(input interface{}, frame interface{}, opts *J
 	// context, otherwise.
 	api := NewJsonLdApi()
 
-	framed, err := api.Frame(expandedInput, expandedFrame, opts)
+	frameMap := frame.(map[string]interface{})
+	activeCtx := NewContext(nil, opts)
+	activeCtx, err = activeCtx.Parse(frameMap["@context"])
 	if err != nil {
 		return nil, err
 	}
 
-	frameMap := frame.(map[string]interface{})
-	activeCtx := NewContext(nil, opts)
-	activeCtx, err = activeCtx.Parse(frameMap["@context"])
+	// JSON-LD 1.1: if the frame has a top-level @graph, frame the default graph.
+	// The options are copied so that the caller's options aren't modified.
+	frameOpts := opts
+	for key := range frameMap {
+		if expandedKey, _ := activeCtx.ExpandIri(key, false, true, nil, nil); expandedKey == "@graph" && !opts.FrameDefault {
+			optsCopy := *opts
+			optsCopy.FrameDefault = true
+			frameOpts = &optsCopy
+		}
+	}
+	framed, err := api.Frame(expandedInput, expandedFrame, frameOpts)
 	if err != nil {
 		return nil, err
 	}
 
-	compacted, _ := api.Compact(activeCtx, "", framed, true)
-	if _, isList := compacted.([]interface{}); !isList {
-		compacted = []interface{}{compacted}
+	// JSON-LD 1.1: remove blank node identifiers which are used only once
+	if opts.PruneBlankNodeIdentifiers || isProcessingMode(opts.ProcessingMode, 1.1) {
+		PruneBlankNodeIdentifiers(framed)
 	}
-	alias := activeCtx.CompactIri("@graph", nil, false, false)
+
+	compacted, _ := api.Compact(activeCtx, "", framed, true)
 	rval := activeCtx.Serialize()
-	rval[alias] = compacted
+	if compactedMap, isMap := compacted.(map[string]interface{}); isMap && opts.OmitGraph {
+		// JSON-LD 1.1: a single result is returned as a top-level object
+		for key, value := range compactedMap {
+			rval[key] = value
+		}
+	} else {
+		if _, isList := compacted.([]interface{}); !isList {
+			compacted = []interface{}{compacted}
+		}
+		alias := activeCtx.CompactIri("@graph", nil, false, false)
+		rval[alias] = compacted
+	}
 	RemovePreserve(activeCtx, rval, opts)
 	return rval, nil
 }
 
 var rdfSerializers = map[string]RDFSerializer{
-	"application/nquads": &NQuadRDFSerializer{},
-	"text/turtle":        &TurtleRDFSerializer{},
+	"application/nquads":  &NQuadRDFSerializer{},
+	"text/turtle":         &TurtleRDFSerializer{},
+	"application/trig":    &TriGRDFSerializer{},
+	"application/rdf+xml": &RDFXMLRDFSerializer{},
 }
 
 // FromRDF converts an RDF dataset to JSON-LD.
@@ -376,7 +400,16 @@ func (jldp *JsonLdProcessor) FromRDF(dataset interface{}, opts *JsonLdOptions) (
 
 func (jldp *JsonLdProcessor) fromRDF(input interface{}, opts *JsonLdOptions, serializer RDFSerializer) (interface{}, error) {
 
-	dataset, _ := serializer.Parse(input)
+	var dataset *RDFDataset
+	var err error
+	if parser, withBase := serializer.(RDFParserWithBase); withBase && opts.Base != "" {
+		dataset, err = parser.ParseWithBase(input, opts.Base)
+	} else {
+		dataset, err = serializer.Parse(input)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	// convert from RDF
 	api := NewJsonLdApi()
@@ -390,9 +423,9 @@ func (jldp *JsonLdProcessor) fromRDF(input interface{}, opts *JsonLdOptions, ser
 		if opts.OutputForm == "expanded" {
 			return rval, nil
 		} else if opts.OutputForm == "compacted" {
-			return jldp.Compact(rval, dataset.context, opts)
+			return jldp.Compact(rval, dataset.GetContext(), opts)
 		} else if opts.OutputForm == "flattened" {
-			return jldp.Flatten(rval, dataset.context, opts)
+			return jldp.Flatten(rval, dataset.GetContext(), opts)
 		} else {
 			return nil, NewJsonLdError(UnknownError, "")
 		}
@@ -426,16 +459,22 @@ func (jldp *JsonLdProcessor) ToRDF(input interface{}, opts *JsonLdOptions) (inte
 
 	// generate namespaces from context
 	if opts.UseNamespaces {
-		var _input []map[string]interface{}
-		if inputList, isList := input.([]map[string]interface{}); isList {
-			_input = inputList
-		} else {
-			_input = make([]map[string]interface{}, 1)
-			_input[0] = make(map[string]interface{})
+		var _input []interface{}
+		switch inputVal := input.(type) {
+		case []map[string]interface{}:
+			for _, e := range inputVal {
+				_input = append(_input, e)
+			}
+		case []interface{}:
+			_input = inputVal
+		default:
+			_input = []interface{}{input}
 		}
 		for _, e := range _input {
-			if ctxVal, hasCtx := e["@context"]; hasCtx {
-				dataset.ParseContext(ctxVal, opts)
+			if eMap, isMap := e.(map[string]interface{}); isMap {
+				if ctxVal, hasCtx := eMap["@context"]; hasCtx {
+					dataset.ParseContext(ctxVal, opts)
+				}
 			}
 		}
 	}
@@ -80,6 +80,8 @@ func NewMockServer(base string, testFolder string) *MockServer {
 				if contentType == "" {
 					if strings.HasSuffix(u, ".jsonld") {
 						contentType = "application/ld+json"
+					} else if strings.HasSuffix(u, ".html") {
+						contentType = "text/html"
 					} else {
 						contentType = "application/json"
 					}
@@ -94,6 +96,8 @@ func NewMockServer(base string, testFolder string) *MockServer {
 					}
 					w.WriteHeader(http.StatusOK)
 					w.Write(inputBytes)
+					// documents loaded after this one (such as alternate documents) use the default content type
+					mockServer.ContentType = ""
 				} else {
 					w.WriteHeader(http.StatusNotFound)
 				}
@@ -155,6 +159,21 @@ func (ms *MockServer) Close() {
 	}
 }
 
+// unsupportedTests lists JSON-LD 1.1 tests (keyed by manifest file name and test ID)
+// which cover features this library doesn't implement yet.
+var unsupportedTests = map[string]bool{
+	// these tests predate non-propagated type-scoped contexts
+	"compact-manifest.jsonld#tm010": true,
+	"expand-manifest.jsonld#tm011":  true,
+
+	// this test expects json-ld-1.1 to be the default processing mode
+	"compact-manifest.jsonld#ta038": true,
+
+	// this test expects json-ld-1.1 to be the default processing mode
+	// and @preserve values to be compacted using the term of the property
+	"frame-manifest.jsonld#t0051": true,
+}
+
 type TestDefinition struct {
 	Id               string
 	Name             string
@@ -248,6 +267,11 @@ func TestSuite(t *testing.T) {
 
 	SequenceLoop:
 		for _, td := range testList {
+			if _, skip := unsupportedTests[manifestName+td.Id]; skip {
+				log.Println("Skipping unsupported test", td.Id, ":", td.Name)
+				continue
+			}
+
 			// ToRDF tests with a reference to RFC3986 don't agree with Go implementation of RFC 3986
 			// (see url.URL.ResolveReference(). Skipping for now, as other JSON-LD implementations do.
 			purpose := td.Raw["purpose"]
@@ -269,17 +293,12 @@ func TestSuite(t *testing.T) {
 				testOpts := td.Option
 
 				if value, hasValue := testOpts["specVersion"]; hasValue {
-					// this library supports JSON-LD 1.0 spec only
-					if value != "json-ld-1.0" {
+					if value != JsonLd_1_0 && value != JsonLd_1_1 {
 						continue
 					}
 				}
 
 				if value, hasValue := testOpts["processingMode"]; hasValue {
-					// this library supports JSON-LD 1.0 spec only
-					if value != "json-ld-1.0" {
-						continue
-					}
 					options.ProcessingMode = value.(string)
 				}
 
@@ -303,6 +322,37 @@ func TestSuite(t *testing.T) {
 				if value, hasValue := testOpts["produceGeneralizedRdf"]; hasValue {
 					options.ProduceGeneralizedRdf = value.(bool)
 				}
+				if value, hasValue := testOpts["rdfDirection"]; hasValue {
+					options.RdfDirection = value.(string)
+				}
+				if value, hasValue := testOpts["embed"]; hasValue {
+					options.Embed = Embed(value.(string))
+				}
+				if value, hasValue := testOpts["requireAll"]; hasValue {
+					options.RequireAll = value.(bool)
+				}
+				if value, hasValue := testOpts["omitGraph"]; hasValue {
+					options.OmitGraph = value.(bool)
+				}
+				if value, hasValue := testOpts["pruneBlankNodeIdentifiers"]; hasValue {
+					options.PruneBlankNodeIdentifiers = value.(bool)
+				}
+				if value, hasValue := testOpts["frameDefault"]; hasValue {
+					options.FrameDefault = value.(bool)
+				}
+				if value, hasValue := testOpts["extractAllScripts"]; hasValue {
+					options.ExtractAllScripts = value.(bool)
+				}
+				if value, hasValue := testOpts["requestProfile"]; hasValue {
+					options.RequestProfile = make([]string, 0)
+					if valueList, isList := value.([]interface{}); isList {
+						for _, profile := range valueList {
+							options.RequestProfile = append(options.RequestProfile, profile.(string))
+						}
+					} else {
+						options.RequestProfile = append(options.RequestProfile, value.(string))
+					}
+				}
 
 				if value, hasValue := testOpts["contentType"]; hasValue {
 					returnContentType = value.(string)
@@ -371,7 +421,7 @@ func TestSuite(t *testing.T) {
 				assert.NoError(t, err)
 				input := string(inputBytes)
 
-				result, err = proc.FromRDF(input, options)
+				result, opError = proc.FromRDF(input, options)
 			case "jld:ToRDFTest":
 				log.Println("Running ToRDF test", td.Id, ":", td.Name)
 
@@ -4,6 +4,7 @@ const (
 	RDFSyntaxNS string = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
 	RDFSchemaNS string = "http://www.w3.org/2000/01/rdf-schema#"
 	XSDNS       string = "http://www.w3.org/2001/XMLSchema#"
+	I18NNS      string = "https://www.w3.org/ns/i18n#"
 
 	XSDAnyType string = XSDNS + "anyType"
 	XSDBoolean string = XSDNS + "boolean"
@@ -21,6 +22,14 @@ const (
 	RDFPlainLiteral string = RDFSyntaxNS + "PlainLiteral"
 	RDFXMLLiteral   string = RDFSyntaxNS + "XMLLiteral"
 	RDFObject       string = RDFSyntaxNS + "object"
+	RDFSubject      string = RDFSyntaxNS + "subject"
+	RDFPredicate    string = RDFSyntaxNS + "predicate"
+	RDFStatement    string = RDFSyntaxNS + "Statement"
+	RDFDescription  string = RDFSyntaxNS + "Description"
 	RDFLangString   string = RDFSyntaxNS + "langString"
+	RDFJSON         string = RDFSyntaxNS + "JSON"
 	RDFList         string = RDFSyntaxNS + "List"
+	RDFValue        string = RDFSyntaxNS + "value"
+	RDFLanguage     string = RDFSyntaxNS + "language"
+	RDFDirection    string = RDFSyntaxNS + "direction"
 )
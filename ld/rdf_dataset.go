@@ -103,6 +103,12 @@ type RDFSerializerTo interface {
 	SerializeTo(w io.Writer, dataset *RDFDataset) error
 }
 
+// RDFParserWithBase can parse RDF documents resolving relative IRIs against the base IRI
+// of the document.
+type RDFParserWithBase interface {
+	ParseWithBase(input interface{}, base string) (*RDFDataset, error)
+}
+
 // NewRDFDataset creates a new instance of RDFDataset.
 func NewRDFDataset() *RDFDataset {
 	ds := &RDFDataset{
@@ -178,6 +184,20 @@ var nilIRI = NewIRI(RDFNil)
 // GraphToRDF creates an array of RDF triples for the given graph.
 func (ds *RDFDataset) GraphToRDF(graphName string, graph map[string]interface{}, issuer *IdentifierIssuer,
 	produceGeneralizedRdf bool) {
+	opts := NewJsonLdOptions("")
+	opts.ProduceGeneralizedRdf = produceGeneralizedRdf
+	// errors can't be reported here: a graph with values which can't be converted,
+	// such as JSON literals which can't be canonicalized, isn't added
+	_ = ds.GraphToRDFWithOptions(graphName, graph, issuer, opts)
+}
+
+// GraphToRDFWithOptions creates an array of RDF triples for the given graph
+// using the ProduceGeneralizedRdf and RdfDirection options. An error is returned
+// if a value of the graph can't be converted to RDF.
+func (ds *RDFDataset) GraphToRDFWithOptions(graphName string, graph map[string]interface{}, issuer *IdentifierIssuer,
+	opts *JsonLdOptions) error {
+	produceGeneralizedRdf := opts.ProduceGeneralizedRdf
+	rdfDirection := opts.RdfDirection
 	// 4.2)
 	triples := make([]*Quad, 0)
 	// 4.3)
@@ -232,12 +252,22 @@ func (ds *RDFDataset) GraphToRDF(graphName string, graph map[string]interface{},
 					var firstBNode Node
 					firstBNode = nilIRI
 					if len(list) > 0 {
-						last = objectToRDF(list[len(list)-1])
+						var literalTriples []*Quad
+						var err error
+						last, literalTriples, err = objectToRDF(list[len(list)-1], issuer, graphName, rdfDirection)
+						if err != nil {
+							return err
+						}
+						triples = append(triples, literalTriples...)
 						firstBNode = NewBlankNode(issuer.GetId(""))
 					}
 					triples = append(triples, NewQuad(subject, predicate, firstBNode, graphName))
 					for i := 0; i < len(list)-1; i++ {
-						object := objectToRDF(list[i])
+						object, literalTriples, err := objectToRDF(list[i], issuer, graphName, rdfDirection)
+						if err != nil {
+							return err
+						}
+						triples = append(triples, literalTriples...)
 						triples = append(triples, NewQuad(firstBNode, first, object, graphName))
 						restBNode := NewBlankNode(issuer.GetId(""))
 						triples = append(triples, NewQuad(firstBNode, rest, restBNode, graphName))
@@ -249,9 +279,13 @@ func (ds *RDFDataset) GraphToRDF(graphName string, graph map[string]interface{},
 					}
 				} else {
 					// convert value or node object to triple
-					object := objectToRDF(item)
+					object, literalTriples, err := objectToRDF(item, issuer, graphName, rdfDirection)
+					if err != nil {
+						return err
+					}
 					if object != nil {
 						triples = append(triples, NewQuad(subject, predicate, object, graphName))
+						triples = append(triples, literalTriples...)
 					}
 				}
 			}
@@ -259,6 +293,7 @@ func (ds *RDFDataset) GraphToRDF(graphName string, graph map[string]interface{},
 	}
 
 	ds.Graphs[graphName] = triples
+	return nil
 }
 
 // GetQuads returns a list of quads for the given graph
@@ -0,0 +1,1142 @@
+package ld
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// xmlNS is the namespace of the xml prefix.
+const xmlNS = "http://www.w3.org/XML/1998/namespace"
+
+// RDFXMLRDFSerializer parses and serializes RDF/XML data.
+type RDFXMLRDFSerializer struct {
+}
+
+// Parse RDF/XML from io.Reader, []byte or string into an RDFDataset.
+// The namespaces declared in the document are set as namespaces of the dataset.
+func (s *RDFXMLRDFSerializer) Parse(input interface{}) (*RDFDataset, error) {
+	return ParseRDFXMLFrom(input)
+}
+
+// ParseWithBase parses RDF/XML like Parse, resolving relative IRIs against the base IRI
+// of the document unless xml:base is used.
+func (s *RDFXMLRDFSerializer) ParseWithBase(input interface{}, base string) (*RDFDataset, error) {
+	return ParseRDFXMLWithBase(input, base)
+}
+
+// SerializeTo writes the default graph of RDFDataset as RDF/XML into a writer.
+// The namespaces of the dataset are used as prefixes.
+func (s *RDFXMLRDFSerializer) SerializeTo(w io.Writer, dataset *RDFDataset) error {
+	xw := newRDFXMLWriter(dataset)
+	body, err := xw.graphToRDFXML(dataset.GetQuads("@default"))
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xw.header()+body+"</rdf:RDF>\n"); err != nil {
+		return NewJsonLdError(IOError, err)
+	}
+	return nil
+}
+
+// Serialize the default graph of an RDFDataset into an RDF/XML string.
+func (s *RDFXMLRDFSerializer) Serialize(dataset *RDFDataset) (interface{}, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := s.SerializeTo(buf, dataset); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// ParseRDFXMLFrom parses RDF in the form of RDF/XML from io.Reader, []byte or string.
+// See https://www.w3.org/TR/rdf-syntax-grammar/
+func ParseRDFXMLFrom(o interface{}) (*RDFDataset, error) {
+	return ParseRDFXMLWithBase(o, "")
+}
+
+// ParseRDFXMLWithBase parses RDF in the form of RDF/XML from io.Reader, []byte or string,
+// resolving relative IRIs against the given base IRI of the document.
+func ParseRDFXMLWithBase(o interface{}, base string) (*RDFDataset, error) {
+	input, err := readRDFInput(o)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &rdfxmlParser{
+		base:    base,
+		dataset: NewRDFDataset(),
+		issuer:  NewIdentifierIssuer("_:b"),
+		seen:    make(map[string]bool),
+		ids:     make(map[string]bool),
+	}
+	root, err := p.readDocument(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.is(RDFSyntaxNS + "RDF") {
+		for _, child := range root.childElements() {
+			if _, err := p.processNodeElement(child); err != nil {
+				return nil, err
+			}
+		}
+		if root.hasText() {
+			return nil, p.errorf("unexpected text in rdf:RDF")
+		}
+	} else if _, err := p.processNodeElement(root); err != nil {
+		return nil, err
+	}
+	return p.dataset, nil
+}
+
+// ParseRDFXML parses RDF in the form of RDF/XML.
+func ParseRDFXML(input string) (*RDFDataset, error) {
+	return ParseRDFXMLFrom(input)
+}
+
+// xmlElement is an element of an XML document, with resolved namespaces.
+type xmlElement struct {
+	name   xml.Name
+	prefix string
+	attrs  []xmlAttribute
+	// content holds the child *xmlElement, xml.CharData, xml.Comment and xml.ProcInst nodes
+	content []interface{}
+	// namespaces holds the namespaces in scope, keyed by prefix
+	namespaces map[string]string
+
+	base string
+	lang string
+}
+
+// xmlAttribute is an attribute of an XML element, with a resolved namespace.
+type xmlAttribute struct {
+	name   xml.Name
+	prefix string
+	value  string
+}
+
+// is returns true if the expanded name of the element is iri.
+func (e *xmlElement) is(iri string) bool {
+	return e.name.Space+e.name.Local == iri
+}
+
+// attr returns the value of the attribute with the given namespace and local name.
+func (e *xmlElement) attr(space string, local string) (string, bool) {
+	for _, a := range e.attrs {
+		if a.name.Space == space && a.name.Local == local {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// childElements returns the child elements of the element.
+func (e *xmlElement) childElements() []*xmlElement {
+	children := make([]*xmlElement, 0)
+	for _, c := range e.content {
+		if child, isElement := c.(*xmlElement); isElement {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// text returns the character data of the element.
+func (e *xmlElement) text() string {
+	var buf bytes.Buffer
+	for _, c := range e.content {
+		if data, isData := c.(xml.CharData); isData {
+			buf.Write(data)
+		}
+	}
+	return buf.String()
+}
+
+// hasText returns true if the element contains character data other than whitespace.
+func (e *xmlElement) hasText() bool {
+	return strings.TrimSpace(e.text()) != ""
+}
+
+var rEntityDecl = regexp.MustCompile(`<!ENTITY\s+([^\s%]+)\s+(?:"([^"]*)"|'([^']*)')`)
+
+// rdfxmlParser converts an RDF/XML document into an RDF dataset.
+type rdfxmlParser struct {
+	// base is the base IRI of the document
+	base    string
+	dataset *RDFDataset
+	issuer  *IdentifierIssuer
+	seen    map[string]bool
+	// ids holds the IRIs generated by rdf:ID, which must be unique
+	ids map[string]bool
+}
+
+// errorf returns a syntax error.
+func (p *rdfxmlParser) errorf(format string, args ...interface{}) error {
+	return NewJsonLdError(SyntaxError, fmt.Errorf("Error while parsing RDF/XML; %s",
+		fmt.Sprintf(format, args...)))
+}
+
+// readDocument reads the XML document into a tree of elements, resolving namespaces
+// and the scoped xml:base and xml:lang attributes. Entities declared in the DTD are expanded.
+func (p *rdfxmlParser) readDocument(input string) (*xmlElement, error) {
+	d := xml.NewDecoder(strings.NewReader(input))
+	d.Entity = make(map[string]string)
+
+	var root *xmlElement
+	stack := make([]*xmlElement, 0)
+	for {
+		token, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+
+		var parent *xmlElement
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		switch t := token.(type) {
+		case xml.Directive:
+			for _, match := range rEntityDecl.FindAllStringSubmatch(string(t), -1) {
+				d.Entity[match[1]] = match[2] + match[3]
+			}
+		case xml.StartElement:
+			e, err := p.newElement(t, parent)
+			if err != nil {
+				return nil, err
+			}
+			if parent != nil {
+				parent.content = append(parent.content, e)
+			} else if root == nil {
+				root = e
+			} else {
+				return nil, p.errorf("more than one root element")
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			if parent == nil || parent.prefix != t.Name.Space || parent.name.Local != t.Name.Local {
+				return nil, p.errorf("unexpected end element %s", t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData, xml.Comment, xml.ProcInst:
+			if parent != nil {
+				parent.content = append(parent.content, xml.CopyToken(t))
+			}
+		}
+	}
+	if root == nil || len(stack) > 0 {
+		return nil, p.errorf("unexpected end of document")
+	}
+	return root, nil
+}
+
+// newElement creates an element from a start element token, resolving its namespaces.
+func (p *rdfxmlParser) newElement(t xml.StartElement, parent *xmlElement) (*xmlElement, error) {
+	e := &xmlElement{
+		name:       xml.Name{Local: t.Name.Local},
+		prefix:     t.Name.Space,
+		namespaces: make(map[string]string),
+	}
+	if parent != nil {
+		for prefix, ns := range parent.namespaces {
+			e.namespaces[prefix] = ns
+		}
+		e.base = parent.base
+		e.lang = parent.lang
+	} else if i := strings.Index(p.base, "#"); i >= 0 {
+		e.base = p.base[:i]
+	} else {
+		e.base = p.base
+	}
+
+	// namespace declarations
+	for _, a := range t.Attr {
+		if a.Name.Space == "xmlns" {
+			e.namespaces[a.Name.Local] = a.Value
+			if a.Value != "" {
+				p.dataset.SetNamespace(a.Name.Local, a.Value)
+			}
+		} else if a.Name.Space == "" && a.Name.Local == "xmlns" {
+			e.namespaces[""] = a.Value
+		}
+	}
+
+	space, err := p.resolvePrefix(e, e.prefix, true)
+	if err != nil {
+		return nil, err
+	}
+	e.name.Space = space
+
+	for _, a := range t.Attr {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		space, err := p.resolvePrefix(e, a.Name.Space, false)
+		if err != nil {
+			return nil, err
+		}
+		e.attrs = append(e.attrs, xmlAttribute{
+			name:   xml.Name{Space: space, Local: a.Name.Local},
+			prefix: a.Name.Space,
+			value:  a.Value,
+		})
+		if space == xmlNS {
+			switch a.Name.Local {
+			case "base":
+				// the base IRI doesn't include the fragment
+				e.base = resolveIRIRef(e.base, a.Value)
+				if i := strings.Index(e.base, "#"); i >= 0 {
+					e.base = e.base[:i]
+				}
+			case "lang":
+				e.lang = a.Value
+			}
+		}
+	}
+	return e, nil
+}
+
+// resolvePrefix returns the namespace of the given prefix in the scope of the element.
+// Unprefixed attributes have no namespace.
+func (p *rdfxmlParser) resolvePrefix(e *xmlElement, prefix string, isElement bool) (string, error) {
+	if prefix == "xml" {
+		return xmlNS, nil
+	}
+	if prefix == "" && !isElement {
+		return "", nil
+	}
+	ns, declared := e.namespaces[prefix]
+	if !declared && prefix != "" {
+		return "", p.errorf("undeclared namespace prefix %s", prefix)
+	}
+	return ns, nil
+}
+
+// emit adds a triple to the default graph.
+func (p *rdfxmlParser) emit(subject Node, predicate string, object Node) {
+	quad := NewQuad(subject, NewIRI(predicate), object, "@default")
+	key := toNQuad(quad, "")
+	if p.seen[key] {
+		return
+	}
+	p.seen[key] = true
+	p.dataset.Graphs["@default"] = append(p.dataset.Graphs["@default"], quad)
+}
+
+// reify emits the reification triples of a statement identified by an rdf:ID.
+func (p *rdfxmlParser) reify(id Node, subject Node, predicate string, object Node) {
+	p.emit(id, RDFType, NewIRI(RDFStatement))
+	p.emit(id, RDFSubject, subject)
+	p.emit(id, RDFPredicate, NewIRI(predicate))
+	p.emit(id, RDFObject, object)
+}
+
+var rNCName = regexp.MustCompile(`^[\pL_][\pL\pN_.\-\x{00B7}\x{0300}-\x{036F}\x{203F}-\x{2040}]*$`)
+
+// idToIRI returns the IRI of an rdf:ID, which must be unique in the scope of a base IRI.
+func (p *rdfxmlParser) idToIRI(id string, base string) (Node, error) {
+	if !rNCName.MatchString(id) {
+		return nil, p.errorf("invalid rdf:ID %s", id)
+	}
+	iri := resolveIRIRef(base, "#"+id)
+	if p.ids[iri] {
+		return nil, p.errorf("duplicate rdf:ID %s", id)
+	}
+	p.ids[iri] = true
+	return NewIRI(iri), nil
+}
+
+// nodeIDToBlankNode returns the blank node of an rdf:nodeID.
+func (p *rdfxmlParser) nodeIDToBlankNode(nodeID string) (Node, error) {
+	if !rNCName.MatchString(nodeID) {
+		return nil, p.errorf("invalid rdf:nodeID %s", nodeID)
+	}
+	return NewBlankNode(p.issuer.GetId("_:" + nodeID)), nil
+}
+
+// isSyntaxAttribute returns true for the attributes which don't produce triples:
+// xml attributes and the RDF syntax attributes.
+func isSyntaxAttribute(a xmlAttribute) bool {
+	if a.name.Space == xmlNS || a.name.Space == "" {
+		// unqualified attributes aren't allowed in RDF/XML and are ignored
+		return true
+	}
+	if a.name.Space != RDFSyntaxNS {
+		return false
+	}
+	switch a.name.Local {
+	case "ID", "about", "parseType", "resource", "nodeID", "datatype":
+		return true
+	}
+	return false
+}
+
+// checkSyntaxAttributes raises an error for the RDF syntax attributes of e which aren't
+// allowed by the given kind of element.
+func (p *rdfxmlParser) checkSyntaxAttributes(e *xmlElement, kind string, allowed ...string) error {
+	for _, a := range e.attrs {
+		if a.name.Space != RDFSyntaxNS || !isSyntaxAttribute(a) {
+			continue
+		}
+		isAllowed := false
+		for _, name := range allowed {
+			if a.name.Local == name {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			return p.errorf("rdf:%s is not allowed on a %s", a.name.Local, kind)
+		}
+	}
+	return nil
+}
+
+// checkName raises an error for the RDF names which aren't allowed as node elements,
+// property elements or property attributes.
+func (p *rdfxmlParser) checkName(space string, local string, kind string) error {
+	if isForbiddenRDFName(space, local, kind) {
+		return p.errorf("rdf:%s is not allowed as a %s", local, kind)
+	}
+	return nil
+}
+
+// isForbiddenRDFName returns true for the RDF names which aren't allowed as the given kind of
+// node element, property element or property attribute.
+func isForbiddenRDFName(space string, local string, kind string) bool {
+	if space != RDFSyntaxNS {
+		return false
+	}
+	switch local {
+	case "RDF", "ID", "about", "parseType", "resource", "nodeID", "datatype", "aboutEach",
+		"aboutEachPrefix", "bagID":
+		return true
+	case "li":
+		return kind == "node element" || kind == "property attribute"
+	case "Description":
+		return kind == "property element" || kind == "property attribute"
+	}
+	return false
+}
+
+// processNodeElement emits the triples of a node element and returns its subject.
+func (p *rdfxmlParser) processNodeElement(e *xmlElement) (Node, error) {
+	if e.name.Space == "" {
+		return nil, p.errorf("element %s has no namespace", e.name.Local)
+	}
+	if err := p.checkName(e.name.Space, e.name.Local, "node element"); err != nil {
+		return nil, err
+	}
+	if err := p.checkSyntaxAttributes(e, "node element", "ID", "about", "nodeID"); err != nil {
+		return nil, err
+	}
+	base, lang := e.base, e.lang
+
+	// the subject
+	var subject Node
+	id, hasID := e.attr(RDFSyntaxNS, "ID")
+	nodeID, hasNodeID := e.attr(RDFSyntaxNS, "nodeID")
+	about, hasAbout := e.attr(RDFSyntaxNS, "about")
+	if (hasID && hasNodeID) || (hasID && hasAbout) || (hasNodeID && hasAbout) {
+		return nil, p.errorf("only one of rdf:ID, rdf:nodeID and rdf:about is allowed")
+	}
+	var err error
+	switch {
+	case hasID:
+		subject, err = p.idToIRI(id, base)
+	case hasNodeID:
+		subject, err = p.nodeIDToBlankNode(nodeID)
+	case hasAbout:
+		subject = NewIRI(resolveIRIRef(base, about))
+	default:
+		subject = NewBlankNode(p.issuer.GetId(""))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.is(RDFDescription) {
+		p.emit(subject, RDFType, NewIRI(e.name.Space+e.name.Local))
+	}
+
+	// property attributes
+	for _, a := range e.attrs {
+		if isSyntaxAttribute(a) {
+			continue
+		}
+		if err := p.checkName(a.name.Space, a.name.Local, "property attribute"); err != nil {
+			return nil, err
+		}
+		if a.name.Space+a.name.Local == RDFType {
+			p.emit(subject, RDFType, NewIRI(resolveIRIRef(base, a.value)))
+		} else {
+			p.emit(subject, a.name.Space+a.name.Local, newLangLiteral(a.value, lang))
+		}
+	}
+
+	// property elements
+	if e.hasText() {
+		return nil, p.errorf("unexpected text in node element %s", e.name.Local)
+	}
+	li := 1
+	for _, child := range e.childElements() {
+		if err := p.processPropertyElement(child, subject, &li); err != nil {
+			return nil, err
+		}
+	}
+	return subject, nil
+}
+
+// newLangLiteral creates a plain literal, with a language tag if lang isn't empty.
+func newLangLiteral(value string, lang string) Node {
+	if lang != "" {
+		return NewLiteral(value, RDFLangString, lang)
+	}
+	return NewLiteral(value, XSDString, "")
+}
+
+// processPropertyElement emits the triples of a property element of the given subject.
+// li is the counter of the rdf:li elements of the subject.
+func (p *rdfxmlParser) processPropertyElement(e *xmlElement, subject Node, li *int) error {
+	if e.name.Space == "" {
+		return p.errorf("element %s has no namespace", e.name.Local)
+	}
+	if err := p.checkName(e.name.Space, e.name.Local, "property element"); err != nil {
+		return err
+	}
+	err := p.checkSyntaxAttributes(e, "property element", "ID", "parseType", "resource", "nodeID",
+		"datatype")
+	if err != nil {
+		return err
+	}
+	base, lang := e.base, e.lang
+
+	predicate := e.name.Space + e.name.Local
+	if e.is(RDFSyntaxNS + "li") {
+		predicate = RDFSyntaxNS + "_" + strconv.Itoa(*li)
+		*li++
+	}
+
+	var reificationID Node
+	if id, hasID := e.attr(RDFSyntaxNS, "ID"); hasID {
+		if reificationID, err = p.idToIRI(id, base); err != nil {
+			return err
+		}
+	}
+	emit := func(object Node) {
+		p.emit(subject, predicate, object)
+		if reificationID != nil {
+			p.reify(reificationID, subject, predicate, object)
+		}
+	}
+
+	children := e.childElements()
+	if parseType, hasParseType := e.attr(RDFSyntaxNS, "parseType"); hasParseType {
+		for _, a := range e.attrs {
+			if !isSyntaxAttribute(a) || a.name.Space+a.name.Local == RDFSyntaxNS+"resource" ||
+				a.name.Space+a.name.Local == RDFSyntaxNS+"nodeID" ||
+				a.name.Space+a.name.Local == RDFSyntaxNS+"datatype" {
+				return p.errorf("attribute %s is not allowed with rdf:parseType", a.name.Local)
+			}
+		}
+		switch parseType {
+		case "Resource":
+			if e.hasText() {
+				return p.errorf("unexpected text in a rdf:parseType=\"Resource\" element")
+			}
+			object := NewBlankNode(p.issuer.GetId(""))
+			emit(object)
+			objectLI := 1
+			for _, child := range children {
+				if err := p.processPropertyElement(child, object, &objectLI); err != nil {
+					return err
+				}
+			}
+		case "Collection":
+			if e.hasText() {
+				return p.errorf("unexpected text in a rdf:parseType=\"Collection\" element")
+			}
+			items := make([]Node, 0, len(children))
+			for _, child := range children {
+				item, err := p.processNodeElement(child)
+				if err != nil {
+					return err
+				}
+				items = append(items, item)
+			}
+			var head Node = NewIRI(RDFNil)
+			var last Node
+			for _, item := range items {
+				node := NewBlankNode(p.issuer.GetId(""))
+				if last == nil {
+					head = node
+				} else {
+					p.emit(last, RDFRest, node)
+				}
+				p.emit(node, RDFFirst, item)
+				last = node
+			}
+			if last != nil {
+				p.emit(last, RDFRest, NewIRI(RDFNil))
+			}
+			emit(head)
+		default:
+			// "Literal" and unknown parse types are XML literals
+			emit(NewLiteral(canonicalXML(e.content, make(map[string]string)), RDFXMLLiteral, ""))
+		}
+		return nil
+	}
+
+	if len(children) > 0 {
+		// resource property element
+		if len(children) > 1 || e.hasText() {
+			return p.errorf("property element %s must contain a single node element", e.name.Local)
+		}
+		for _, a := range e.attrs {
+			if !isSyntaxAttribute(a) || (a.name.Space == RDFSyntaxNS && a.name.Local != "ID") {
+				return p.errorf("attribute %s is not allowed on a property element with a node element",
+					a.name.Local)
+			}
+		}
+		object, err := p.processNodeElement(children[0])
+		if err != nil {
+			return err
+		}
+		emit(object)
+		return nil
+	}
+
+	resource, hasResource := e.attr(RDFSyntaxNS, "resource")
+	nodeID, hasNodeID := e.attr(RDFSyntaxNS, "nodeID")
+	datatype, hasDatatype := e.attr(RDFSyntaxNS, "datatype")
+	propertyAttrs := make([]xmlAttribute, 0)
+	for _, a := range e.attrs {
+		if !isSyntaxAttribute(a) {
+			if err := p.checkName(a.name.Space, a.name.Local, "property attribute"); err != nil {
+				return err
+			}
+			propertyAttrs = append(propertyAttrs, a)
+		}
+	}
+
+	if hasDatatype || (!hasResource && !hasNodeID && len(propertyAttrs) == 0) {
+		// literal property element
+		if hasResource || hasNodeID || len(propertyAttrs) > 0 {
+			return p.errorf("attribute not allowed on a literal property element %s", e.name.Local)
+		}
+		if hasDatatype {
+			emit(NewLiteral(e.text(), resolveIRIRef(base, datatype), ""))
+		} else {
+			emit(newLangLiteral(e.text(), lang))
+		}
+		return nil
+	}
+
+	// empty property element
+	if e.hasText() {
+		return p.errorf("unexpected text in the empty property element %s", e.name.Local)
+	}
+	var object Node
+	switch {
+	case hasResource && hasNodeID:
+		return p.errorf("rdf:resource and rdf:nodeID can't be used together")
+	case hasResource:
+		object = NewIRI(resolveIRIRef(base, resource))
+	case hasNodeID:
+		var err error
+		if object, err = p.nodeIDToBlankNode(nodeID); err != nil {
+			return err
+		}
+	default:
+		object = NewBlankNode(p.issuer.GetId(""))
+	}
+	for _, a := range propertyAttrs {
+		if a.name.Space+a.name.Local == RDFType {
+			p.emit(object, RDFType, NewIRI(resolveIRIRef(base, a.value)))
+		} else {
+			p.emit(object, a.name.Space+a.name.Local, newLangLiteral(a.value, lang))
+		}
+	}
+	emit(object)
+	return nil
+}
+
+// canonicalXML serializes XML content using exclusive XML canonicalization with comments,
+// as required for the value of rdf:parseType="Literal" property elements.
+// rendered holds the namespace declarations already output by the ancestor elements.
+func canonicalXML(content []interface{}, rendered map[string]string) string {
+	var buf bytes.Buffer
+	for _, c := range content {
+		switch node := c.(type) {
+		case xml.CharData:
+			buf.WriteString(escapeCanonicalXML(string(node), false))
+		case xml.Comment:
+			buf.WriteString("<!--" + string(node) + "-->")
+		case xml.ProcInst:
+			buf.WriteString("<?" + node.Target)
+			if len(node.Inst) > 0 {
+				buf.WriteString(" " + string(node.Inst))
+			}
+			buf.WriteString("?>")
+		case *xmlElement:
+			qname := node.name.Local
+			if node.prefix != "" {
+				qname = node.prefix + ":" + node.name.Local
+			}
+			buf.WriteString("<" + qname)
+
+			// the namespaces visibly utilized by the element and its attributes
+			used := map[string]bool{node.prefix: true}
+			for _, a := range node.attrs {
+				if a.prefix != "" && a.prefix != "xml" {
+					used[a.prefix] = true
+				}
+			}
+			prefixes := make([]string, 0, len(used))
+			for prefix := range used {
+				prefixes = append(prefixes, prefix)
+			}
+			sort.Strings(prefixes)
+			childRendered := make(map[string]string, len(rendered))
+			for prefix, ns := range rendered {
+				childRendered[prefix] = ns
+			}
+			for _, prefix := range prefixes {
+				ns := node.namespaces[prefix]
+				if renderedNS, isRendered := rendered[prefix]; (isRendered && renderedNS == ns) ||
+					(!isRendered && prefix == "" && ns == "") {
+					continue
+				}
+				if prefix == "" {
+					buf.WriteString(` xmlns="` + escapeCanonicalXML(ns, true) + `"`)
+				} else {
+					buf.WriteString(" xmlns:" + prefix + `="` + escapeCanonicalXML(ns, true) + `"`)
+				}
+				childRendered[prefix] = ns
+			}
+
+			attrs := make([]xmlAttribute, len(node.attrs))
+			copy(attrs, node.attrs)
+			sort.Slice(attrs, func(i, j int) bool {
+				if attrs[i].name.Space != attrs[j].name.Space {
+					return attrs[i].name.Space < attrs[j].name.Space
+				}
+				return attrs[i].name.Local < attrs[j].name.Local
+			})
+			for _, a := range attrs {
+				name := a.name.Local
+				if a.prefix != "" {
+					name = a.prefix + ":" + a.name.Local
+				}
+				buf.WriteString(" " + name + `="` + escapeCanonicalXML(a.value, true) + `"`)
+			}
+
+			buf.WriteString(">" + canonicalXML(node.content, childRendered) + "</" + qname + ">")
+		}
+	}
+	return buf.String()
+}
+
+// escapeCanonicalXML escapes text or attribute values as required by XML canonicalization.
+func escapeCanonicalXML(s string, attribute bool) string {
+	var buf bytes.Buffer
+	for _, c := range s {
+		switch {
+		case c == '&':
+			buf.WriteString("&amp;")
+		case c == '<':
+			buf.WriteString("&lt;")
+		case c == '>' && !attribute:
+			buf.WriteString("&gt;")
+		case c == '"' && attribute:
+			buf.WriteString("&quot;")
+		case c == '\t' && attribute:
+			buf.WriteString("&#x9;")
+		case c == '\n' && attribute:
+			buf.WriteString("&#xA;")
+		case c == '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	return buf.String()
+}
+
+// rdfxmlWriter writes graphs in RDF/XML, using the namespaces of a dataset as prefixes,
+// typed node elements for subjects with one rdf:type and nested node elements for
+// blank nodes which are referenced once.
+type rdfxmlWriter struct {
+	// namespaces holds the [prefix, IRI] pairs usable as prefixes, longest IRI first
+	namespaces [][2]string
+	// usedPrefixes holds the prefixes which were used while writing
+	usedPrefixes map[string]string
+	// takenPrefixes holds the prefixes which can't be generated
+	takenPrefixes map[string]bool
+	// issuer issues the rdf:nodeID values of blank nodes
+	issuer *IdentifierIssuer
+}
+
+func newRDFXMLWriter(dataset *RDFDataset) *rdfxmlWriter {
+	xw := &rdfxmlWriter{
+		namespaces:    [][2]string{{"rdf", RDFSyntaxNS}},
+		usedPrefixes:  map[string]string{"rdf": RDFSyntaxNS},
+		takenPrefixes: map[string]bool{"rdf": true},
+		issuer:        NewIdentifierIssuer("b"),
+	}
+	for prefix, iri := range dataset.GetNamespaces() {
+		xw.takenPrefixes[prefix] = true
+		// the rdf prefix is reserved for the RDF syntax names, and prefixes starting with
+		// xml are reserved by XML
+		if prefix == "rdf" || iri == RDFSyntaxNS || iri == "" || !rNCName.MatchString(prefix) ||
+			strings.HasPrefix(strings.ToLower(prefix), "xml") {
+			continue
+		}
+		xw.namespaces = append(xw.namespaces, [2]string{prefix, iri})
+	}
+	sort.Slice(xw.namespaces, func(i, j int) bool {
+		a, b := xw.namespaces[i], xw.namespaces[j]
+		if len(a[1]) != len(b[1]) {
+			return len(a[1]) > len(b[1])
+		}
+		return a[0] < b[0]
+	})
+	return xw
+}
+
+// header returns the XML declaration and the rdf:RDF start tag declaring the prefixes
+// used so far.
+func (xw *rdfxmlWriter) header() string {
+	prefixes := make([]string, 0, len(xw.usedPrefixes))
+	for prefix := range xw.usedPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var buf bytes.Buffer
+	buf.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<rdf:RDF")
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&buf, "\n  xmlns:%s=\"%s\"", prefix, escapeCanonicalXML(xw.usedPrefixes[prefix], true))
+	}
+	buf.WriteString(">\n")
+	return buf.String()
+}
+
+// qname returns the qualified name of an IRI. The namespaces of the dataset are used
+// if possible, otherwise the IRI is split before its longest suffix which is an NCName
+// and a prefix is generated for the namespace.
+func (xw *rdfxmlWriter) qname(iri string) (string, bool) {
+	for _, ns := range xw.namespaces {
+		if strings.HasPrefix(iri, ns[1]) && rNCName.MatchString(iri[len(ns[1]):]) {
+			xw.usedPrefixes[ns[0]] = ns[1]
+			return ns[0] + ":" + iri[len(ns[1]):], true
+		}
+	}
+	for i := range iri {
+		if i > 0 && rNCName.MatchString(iri[i:]) {
+			prefix := ""
+			for n := 0; prefix == "" || xw.takenPrefixes[prefix]; n++ {
+				prefix = "ns" + strconv.Itoa(n)
+			}
+			xw.takenPrefixes[prefix] = true
+			xw.namespaces = append(xw.namespaces, [2]string{prefix, iri[:i]})
+			xw.usedPrefixes[prefix] = iri[:i]
+			return prefix + ":" + iri[i:], true
+		}
+	}
+	return "", false
+}
+
+// elementName returns the qualified name of a node or property element for an IRI,
+// or false if the IRI can't be written as the given kind of element.
+func (xw *rdfxmlWriter) elementName(iri string, kind string) (string, bool) {
+	if !isXMLChars(iri) {
+		return "", false
+	}
+	if strings.HasPrefix(iri, RDFSyntaxNS) {
+		local := iri[len(RDFSyntaxNS):]
+		// rdf:li property elements would be read back as rdf:_n, and the type of an
+		// rdf:Description node element would be lost
+		if isForbiddenRDFName(RDFSyntaxNS, local, kind) || local == "li" ||
+			(kind == "node element" && local == "Description") {
+			return "", false
+		}
+	}
+	return xw.qname(iri)
+}
+
+// nodeID returns the rdf:nodeID of a blank node.
+func (xw *rdfxmlWriter) nodeID(label string) string {
+	return xw.issuer.GetId(label)
+}
+
+// rdfxmlGraph holds the state of writing a graph.
+type rdfxmlGraph struct {
+	subjects map[string]*turtleSubject
+	// references counts the uses of each blank node as an object
+	references map[string]int
+	// inlined holds the blank nodes which have been written
+	inlined map[string]bool
+	// labelled holds the blank nodes which must be written with an rdf:nodeID
+	labelled map[string]bool
+}
+
+// graphToRDFXML returns the node elements of the triples of a graph.
+func (xw *rdfxmlWriter) graphToRDFXML(quads []*Quad) (string, error) {
+	g := &rdfxmlGraph{
+		subjects:   make(map[string]*turtleSubject),
+		references: make(map[string]int),
+		inlined:    make(map[string]bool),
+		labelled:   make(map[string]bool),
+	}
+	for _, quad := range quads {
+		if IsBlankNode(quad.Predicate) {
+			return "", NewJsonLdError(InvalidInput,
+				fmt.Sprintf("the blank node predicate %s can't be serialized as RDF/XML", quad.Predicate.GetValue()))
+		}
+		key := quad.Subject.GetValue()
+		subject, hasSubject := g.subjects[key]
+		if !hasSubject {
+			subject = &turtleSubject{node: quad.Subject, properties: make(map[string][]Node)}
+			g.subjects[key] = subject
+		}
+		predicate := quad.Predicate.GetValue()
+		subject.properties[predicate] = append(subject.properties[predicate], quad.Object)
+		if IsBlankNode(quad.Object) {
+			g.references[quad.Object.GetValue()]++
+		}
+	}
+
+	// IRIs first, then blank nodes, which are nested if they are referenced once
+	keys := make([]string, 0, len(g.subjects))
+	for key := range g.subjects {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		bi, bj := IsBlankNode(g.subjects[keys[i]].node), IsBlankNode(g.subjects[keys[j]].node)
+		if bi != bj {
+			return bj
+		}
+		return keys[i] < keys[j]
+	})
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		if IsBlankNode(g.subjects[key].node) && g.references[key] == 1 {
+			continue
+		}
+		if err := xw.writeNodeElement(&buf, g, key, "  "); err != nil {
+			return "", err
+		}
+	}
+	// blank nodes which are only referenced from within their own nested nodes (cycles)
+	for _, key := range keys {
+		if IsBlankNode(g.subjects[key].node) && !g.inlined[key] && g.references[key] == 1 {
+			g.labelled[key] = true
+			if err := xw.writeNodeElement(&buf, g, key, "  "); err != nil {
+				return "", err
+			}
+		}
+	}
+	return buf.String(), nil
+}
+
+// writeNodeElement writes a subject as a node element starting with indent. A subject
+// with one rdf:type is written as a typed node element.
+func (xw *rdfxmlWriter) writeNodeElement(buf *bytes.Buffer, g *rdfxmlGraph, key string, indent string) error {
+	subject := g.subjects[key]
+	g.inlined[key] = true
+
+	name := "rdf:Description"
+	typed := false
+	if types := subject.properties[RDFType]; len(types) == 1 && IsIRI(types[0]) {
+		name, typed = xw.elementName(types[0].GetValue(), "node element")
+		if !typed {
+			name = "rdf:Description"
+		}
+	}
+
+	buf.WriteString(indent + "<" + name)
+	if IsIRI(subject.node) {
+		about, err := escapeRDFXML(key, true)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, " rdf:about=\"%s\"", about)
+	} else if g.labelled[key] || g.references[key] > 1 {
+		fmt.Fprintf(buf, " rdf:nodeID=\"%s\"", xw.nodeID(key))
+	}
+
+	predicates := make([]string, 0, len(subject.properties))
+	for predicate := range subject.properties {
+		if predicate != RDFType || !typed {
+			predicates = append(predicates, predicate)
+		}
+	}
+	if len(predicates) == 0 {
+		buf.WriteString("/>\n")
+		return nil
+	}
+	buf.WriteString(">\n")
+	sort.Strings(predicates)
+
+	for _, predicate := range predicates {
+		propertyName, ok := xw.elementName(predicate, "property element")
+		if !ok {
+			return NewJsonLdError(InvalidInput,
+				fmt.Sprintf("the predicate %s can't be serialized as RDF/XML", predicate))
+		}
+		objects := subject.properties[predicate]
+		sort.SliceStable(objects, func(i, j int) bool {
+			return nodeSortKey(objects[i]) < nodeSortKey(objects[j])
+		})
+		for _, object := range objects {
+			if err := xw.writePropertyElement(buf, g, propertyName, object, indent+"  "); err != nil {
+				return err
+			}
+		}
+	}
+	buf.WriteString(indent + "</" + name + ">\n")
+	return nil
+}
+
+// writePropertyElement writes a property element with its object starting with indent.
+func (xw *rdfxmlWriter) writePropertyElement(buf *bytes.Buffer, g *rdfxmlGraph, name string, object Node,
+	indent string) error {
+	switch node := object.(type) {
+	case *IRI:
+		resource, err := escapeRDFXML(node.Value, true)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s<%s rdf:resource=\"%s\"/>\n", indent, name, resource)
+	case *Literal:
+		var attrs, text string
+		var err error
+		switch {
+		case node.Datatype == RDFLangString:
+			var lang string
+			if lang, err = escapeRDFXML(node.Language, true); err != nil {
+				return err
+			}
+			attrs = fmt.Sprintf(" xml:lang=\"%s\"", lang)
+		case node.Datatype == RDFXMLLiteral && isXMLLiteralContent(node.Value):
+			attrs = " rdf:parseType=\"Literal\""
+			text = node.Value
+		case node.Datatype != XSDString && node.Datatype != "":
+			var datatype string
+			if datatype, err = escapeRDFXML(node.Datatype, true); err != nil {
+				return err
+			}
+			attrs = fmt.Sprintf(" rdf:datatype=\"%s\"", datatype)
+		}
+		if text == "" {
+			if text, err = escapeRDFXML(node.Value, false); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(buf, "%s<%s%s>%s</%s>\n", indent, name, attrs, text, name)
+	default:
+		label := object.GetValue()
+		if _, hasSubject := g.subjects[label]; !hasSubject || g.references[label] != 1 || g.labelled[label] ||
+			g.inlined[label] {
+			fmt.Fprintf(buf, "%s<%s rdf:nodeID=\"%s\"/>\n", indent, name, xw.nodeID(label))
+			return nil
+		}
+		buf.WriteString(indent + "<" + name + ">\n")
+		if err := xw.writeNodeElement(buf, g, label, indent+"  "); err != nil {
+			return err
+		}
+		buf.WriteString(indent + "</" + name + ">\n")
+	}
+	return nil
+}
+
+// isXMLLiteralContent returns true if an XML literal is well-formed XML content which declares
+// all the prefixes it uses, so that it can be written with rdf:parseType="Literal".
+func isXMLLiteralContent(content string) bool {
+	type scope struct {
+		name     xml.Name
+		prefixes map[string]bool
+	}
+	stack := make([]scope, 0)
+	isDeclared := func(prefix string) bool {
+		if prefix == "" || prefix == "xml" {
+			return true
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].prefixes[prefix] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// RawToken keeps the prefixes of names, but doesn't match start and end elements
+	d := xml.NewDecoder(strings.NewReader(content))
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			return len(stack) == 0
+		}
+		if err != nil {
+			return false
+		}
+		switch token := t.(type) {
+		case xml.StartElement:
+			element := scope{name: token.Name, prefixes: make(map[string]bool)}
+			for _, a := range token.Attr {
+				if a.Name.Space == "xmlns" {
+					element.prefixes[a.Name.Local] = true
+				}
+			}
+			stack = append(stack, element)
+			if !isDeclared(token.Name.Space) {
+				return false
+			}
+			for _, a := range token.Attr {
+				if a.Name.Space != "xmlns" && !isDeclared(a.Name.Space) {
+					return false
+				}
+			}
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1].name != token.Name {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		case xml.Directive:
+			return false
+		}
+	}
+}
+
+// isXMLChars returns true if a string only has characters which XML 1.0 can represent.
+func isXMLChars(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !(r == 0x9 || r == 0xA || r == 0xD || (r >= 0x20 && r <= 0xD7FF) || (r >= 0xE000 && r <= 0xFFFD) ||
+			(r >= 0x10000 && r <= 0x10FFFF)) {
+			return false
+		}
+	}
+	return true
+}
+
+// escapeRDFXML escapes text or attribute values, returning an error for the strings which
+// can't be represented in XML 1.0.
+func escapeRDFXML(s string, attribute bool) (string, error) {
+	if !isXMLChars(s) {
+		return "", NewJsonLdError(InvalidInput, fmt.Sprintf("%q can't be serialized as RDF/XML", s))
+	}
+	return escapeCanonicalXML(s, attribute), nil
+}
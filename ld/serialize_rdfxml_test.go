@@ -0,0 +1,465 @@
+package ld_test
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/kazarena/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rdfxmlHeader = `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.org/"`
+
+func parseRDFXMLToNQuads(t *testing.T, input string) string {
+	dataset, err := ParseRDFXML(input)
+	require.Nil(t, err, input)
+	nquads, err := (&NQuadRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+	return nquads.(string)
+}
+
+func TestParseRDFXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "descriptions, property attributes and typed node elements",
+			input: rdfxmlHeader + `>
+				<rdf:Description rdf:about="http://example.org/a" ex:name="A">
+					<ex:knows rdf:resource="http://example.org/b"/>
+				</rdf:Description>
+				<ex:Person rdf:about="http://example.org/b" rdf:type="http://example.org/Agent"/>
+			</rdf:RDF>`,
+			expected: `<http://example.org/a> <http://example.org/knows> <http://example.org/b> .
+<http://example.org/a> <http://example.org/name> "A" .
+<http://example.org/b> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Agent> .
+<http://example.org/b> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Person> .
+`,
+		},
+		{
+			name: "nested node elements, rdf:nodeID and empty property elements",
+			input: rdfxmlHeader + `>
+				<rdf:Description rdf:about="http://example.org/a">
+					<ex:knows><rdf:Description rdf:nodeID="x" ex:name="X"/></ex:knows>
+					<ex:likes rdf:nodeID="x"/>
+					<ex:address ex:city="Paris"/>
+					<ex:nothing/>
+				</rdf:Description>
+			</rdf:RDF>`,
+			expected: `<http://example.org/a> <http://example.org/address> _:b1 .
+<http://example.org/a> <http://example.org/knows> _:b0 .
+<http://example.org/a> <http://example.org/likes> _:b0 .
+<http://example.org/a> <http://example.org/nothing> "" .
+_:b0 <http://example.org/name> "X" .
+_:b1 <http://example.org/city> "Paris" .
+`,
+		},
+		{
+			name: "xml:lang, rdf:datatype and xml:base",
+			input: rdfxmlHeader + ` xml:base="http://example.org/docs/index.rdf#ignored">
+				<rdf:Description rdf:about="a" xml:lang="en">
+					<ex:title>Title</ex:title>
+					<ex:title xml:lang="">No language</ex:title>
+					<ex:size rdf:datatype="http://www.w3.org/2001/XMLSchema#integer">42</ex:size>
+					<ex:self rdf:resource=""/>
+				</rdf:Description>
+				<rdf:Description rdf:ID="b" xml:base="http://example.com/"/>
+			</rdf:RDF>`,
+			expected: `<http://example.org/docs/a> <http://example.org/self> <http://example.org/docs/index.rdf> .
+<http://example.org/docs/a> <http://example.org/size> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/docs/a> <http://example.org/title> "No language" .
+<http://example.org/docs/a> <http://example.org/title> "Title"@en .
+`,
+		},
+		{
+			name: "rdf:parseType Resource and Collection",
+			input: rdfxmlHeader + `>
+				<rdf:Description rdf:about="http://example.org/a">
+					<ex:address rdf:parseType="Resource"><ex:city>Paris</ex:city></ex:address>
+					<ex:list rdf:parseType="Collection">
+						<rdf:Description rdf:about="http://example.org/x"/>
+						<rdf:Description rdf:about="http://example.org/y"/>
+					</ex:list>
+					<ex:empty rdf:parseType="Collection"/>
+				</rdf:Description>
+			</rdf:RDF>`,
+			expected: `<http://example.org/a> <http://example.org/address> _:b0 .
+<http://example.org/a> <http://example.org/empty> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+<http://example.org/a> <http://example.org/list> _:b1 .
+_:b0 <http://example.org/city> "Paris" .
+_:b1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.org/x> .
+_:b1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:b2 .
+_:b2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.org/y> .
+_:b2 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+`,
+		},
+		{
+			name: "rdf:parseType Literal",
+			input: rdfxmlHeader + ` xmlns:h="http://www.w3.org/1999/xhtml">
+				<rdf:Description rdf:about="http://example.org/a">
+					<ex:lit rdf:parseType="Literal"><h:b h:class="c" id='1'>x &amp; y</h:b><!-- note --></ex:lit>
+				</rdf:Description>
+			</rdf:RDF>`,
+			expected: `<http://example.org/a> <http://example.org/lit> "<h:b xmlns:h=\"http://www.w3.org/1999/xhtml\" id=\"1\" h:class=\"c\">x &amp; y</h:b><!-- note -->"^^<http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral> .
+`,
+		},
+		{
+			name: "rdf:li and reification with rdf:ID",
+			input: rdfxmlHeader + ` xml:base="http://example.org/doc">
+				<rdf:Bag rdf:about="http://example.org/bag">
+					<rdf:li>one</rdf:li>
+					<rdf:li rdf:ID="second">two</rdf:li>
+				</rdf:Bag>
+			</rdf:RDF>`,
+			expected: `<http://example.org/bag> <http://www.w3.org/1999/02/22-rdf-syntax-ns#_1> "one" .
+<http://example.org/bag> <http://www.w3.org/1999/02/22-rdf-syntax-ns#_2> "two" .
+<http://example.org/bag> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Bag> .
+<http://example.org/doc#second> <http://www.w3.org/1999/02/22-rdf-syntax-ns#object> "two" .
+<http://example.org/doc#second> <http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate> <http://www.w3.org/1999/02/22-rdf-syntax-ns#_2> .
+<http://example.org/doc#second> <http://www.w3.org/1999/02/22-rdf-syntax-ns#subject> <http://example.org/bag> .
+<http://example.org/doc#second> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement> .
+`,
+		},
+		{
+			name: "entities and a node element as document element",
+			input: `<!DOCTYPE ex:Thing [<!ENTITY ex "http://example.org/">]>
+				<ex:Thing xmlns:ex="http://example.org/" xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+					rdf:about="&ex;thing" ex:label="thing"/>`,
+			expected: `<http://example.org/thing> <http://example.org/label> "thing" .
+<http://example.org/thing> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Thing> .
+`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, parseRDFXMLToNQuads(t, test.input), test.name)
+	}
+}
+
+// rdfxmlTestsBase is the IRI of the W3C RDF/XML test suite, whose layout is used in testdata/rdf-xml.
+const rdfxmlTestsBase = "http://www.w3.org/2013/RDFXMLTests/"
+
+// rdfxmlSkippedTests lists the tests of testdata/rdf-xml which aren't run, with the reason.
+// Every test of the W3C suite which is skipped must be listed here.
+var rdfxmlSkippedTests = map[string]string{}
+
+// TestRDFXMLManifests runs the tests of the manifests in testdata/rdf-xml, which use the format
+// of the W3C RDF/XML test suite: the manifest.ttl of the suite and its test directories, from
+// http://www.w3.org/2013/RDFXMLTests/TESTS.zip, can be copied there as they are. The suite only
+// has TestXMLEval and TestXMLNegativeSyntax tests; other test types fail.
+func TestRDFXMLManifests(t *testing.T) {
+	const mf = "http://www.w3.org/2001/sw/DataAccess/tests/test-manifest#"
+	const rdft = "http://www.w3.org/ns/rdftest#"
+
+	manifests, err := filepath.Glob(filepath.Join("testdata", "rdf-xml", "*manifest.ttl"))
+	require.Nil(t, err)
+	require.NotEmpty(t, manifests)
+
+	for _, manifest := range manifests {
+		data, err := ioutil.ReadFile(manifest)
+		require.Nil(t, err)
+		manifestIRI := rdfxmlTestsBase + filepath.Base(manifest)
+		dataset, err := ParseTurtle("@base <" + manifestIRI + "> .\n" + string(data))
+		require.Nil(t, err, manifest)
+
+		properties := make(map[string]map[string]Node)
+		for _, quad := range dataset.GetQuads("@default") {
+			subject := quad.Subject.GetValue()
+			if properties[subject] == nil {
+				properties[subject] = make(map[string]Node)
+			}
+			properties[subject][quad.Predicate.GetValue()] = quad.Object
+		}
+		readFile := func(iri string) []byte {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", "rdf-xml",
+				filepath.FromSlash(strings.TrimPrefix(iri, rdfxmlTestsBase))))
+			require.Nil(t, err, iri)
+			return data
+		}
+
+		count := 0
+		for list := properties[manifestIRI][mf+"entries"]; list != nil && list.GetValue() != RDFNil; list =
+			properties[list.GetValue()][RDFRest] {
+			testIRI := properties[list.GetValue()][RDFFirst].GetValue()
+			test := properties[testIRI]
+			action := test[mf+"action"].GetValue()
+			count++
+			if reason, skip := rdfxmlSkippedTests[testIRI]; skip {
+				log.Println("Skipping RDF/XML test", testIRI, ":", reason)
+				continue
+			}
+
+			dataset, err := ParseRDFXMLWithBase(readFile(action), action)
+			switch test[RDFType].GetValue() {
+			case rdft + "TestXMLEval":
+				if !assert.Nil(t, err, action) {
+					continue
+				}
+				expected, err := (&NQuadRDFSerializer{}).Parse(string(readFile(test[mf+"result"].GetValue())))
+				require.Nil(t, err, action)
+				assert.Equal(t, canonicalNQuads(t, expected), canonicalNQuads(t, dataset), action)
+			case rdft + "TestXMLNegativeSyntax":
+				assert.NotNil(t, err, action)
+			default:
+				t.Errorf("unsupported test type %s of %s", test[RDFType].GetValue(), testIRI)
+			}
+		}
+		assert.NotZero(t, count, manifest)
+	}
+}
+
+func TestParseRDFXMLErrors(t *testing.T) {
+	inputs := []string{
+		// not well-formed
+		rdfxmlHeader + `><rdf:Description></rdf:RDF>`,
+		// rdf:li as a node element
+		rdfxmlHeader + `><rdf:li/></rdf:RDF>`,
+		// rdf:Description as a property element
+		rdfxmlHeader + `><rdf:Description><rdf:Description/></rdf:Description></rdf:RDF>`,
+		// rdf:li as a property attribute
+		rdfxmlHeader + `><rdf:Description rdf:li="x"/></rdf:RDF>`,
+		// rdf:ID which isn't an NCName
+		rdfxmlHeader + `><rdf:Description rdf:ID="333-555"/></rdf:RDF>`,
+		// duplicate rdf:ID
+		rdfxmlHeader + `><rdf:Description rdf:ID="a"/><rdf:Description rdf:ID="a"/></rdf:RDF>`,
+		// rdf:nodeID which isn't an NCName
+		rdfxmlHeader + `><rdf:Description rdf:nodeID="a:b"/></rdf:RDF>`,
+		// rdf:about and rdf:nodeID
+		rdfxmlHeader + `><rdf:Description rdf:about="http://example.org/a" rdf:nodeID="a"/></rdf:RDF>`,
+		// rdf:resource, rdf:datatype and rdf:parseType on a node element
+		rdfxmlHeader + `><rdf:Description rdf:resource="http://example.org/a"/></rdf:RDF>`,
+		rdfxmlHeader + `><ex:Thing rdf:datatype="http://example.org/dt"/></rdf:RDF>`,
+		rdfxmlHeader + `><rdf:Description rdf:parseType="Resource"/></rdf:RDF>`,
+		// rdf:about on property elements
+		rdfxmlHeader + `><rdf:Description><ex:p rdf:about="http://example.org/a" rdf:resource="http://example.org/b"/></rdf:Description></rdf:RDF>`,
+		rdfxmlHeader + `><rdf:Description><ex:p rdf:about="http://example.org/a">x</ex:p></rdf:Description></rdf:RDF>`,
+		rdfxmlHeader + `><rdf:Description><ex:p rdf:about="http://example.org/a" rdf:parseType="Resource"/></rdf:Description></rdf:RDF>`,
+		// two node elements in a property element
+		rdfxmlHeader + `><rdf:Description><ex:p><rdf:Description/><rdf:Description/></ex:p></rdf:Description></rdf:RDF>`,
+		// undeclared prefix
+		rdfxmlHeader + `><foo:Thing/></rdf:RDF>`,
+	}
+	for _, input := range inputs {
+		_, err := ParseRDFXML(input)
+		if assert.NotNil(t, err, input) {
+			assert.Equal(t, SyntaxError, err.(*JsonLdError).Code, input)
+		}
+	}
+}
+
+func TestFromRDFXML(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.Format = "application/rdf+xml"
+	opts.OutputForm = "compacted"
+
+	doc, err := proc.FromRDF(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+		xmlns:skos="http://www.w3.org/2004/02/skos/core#">
+		<skos:Concept rdf:about="http://example.org/concept">
+			<skos:prefLabel xml:lang="en">Concept</skos:prefLabel>
+		</skos:Concept>
+	</rdf:RDF>`, opts)
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"@context": map[string]interface{}{
+			"rdf":  "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+			"skos": "http://www.w3.org/2004/02/skos/core#",
+		},
+		"@id":   "http://example.org/concept",
+		"@type": "skos:Concept",
+		"skos:prefLabel": map[string]interface{}{
+			"@language": "en",
+			"@value":    "Concept",
+		},
+	}, doc)
+}
+
+func TestSerializeRDFXML(t *testing.T) {
+	dataset, err := ParseTurtle(`@prefix ex: <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+@prefix rdf: <http://www.w3.org/1999/02/22-rdf-syntax-ns#> .
+
+ex:alice a foaf:Person ;
+    foaf:name "Alice"@en ;
+    foaf:age 42 ;
+    foaf:knows [ a foaf:Person ; foaf:name "Bob" ; foaf:knows _:carol ], _:carol ;
+    ex:note "a & <b>" ;
+    ex:markup "<b>bold</b>"^^rdf:XMLLiteral ;
+    <http://example.com/vocab#rank> "1" .
+ex:thing a ex:A, ex:B .
+_:carol foaf:name "Carol" .
+`)
+	require.Nil(t, err)
+
+	serialized, err := (&RDFXMLRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF
+  xmlns:ex="http://example.org/"
+  xmlns:foaf="http://xmlns.com/foaf/0.1/"
+  xmlns:ns0="http://example.com/vocab#"
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <foaf:Person rdf:about="http://example.org/alice">
+    <ns0:rank>1</ns0:rank>
+    <ex:markup rdf:parseType="Literal"><b>bold</b></ex:markup>
+    <ex:note>a &amp; &lt;b&gt;</ex:note>
+    <foaf:age rdf:datatype="http://www.w3.org/2001/XMLSchema#integer">42</foaf:age>
+    <foaf:knows>
+      <foaf:Person>
+        <foaf:knows rdf:nodeID="b0"/>
+        <foaf:name>Bob</foaf:name>
+      </foaf:Person>
+    </foaf:knows>
+    <foaf:knows rdf:nodeID="b0"/>
+    <foaf:name xml:lang="en">Alice</foaf:name>
+  </foaf:Person>
+  <rdf:Description rdf:about="http://example.org/thing">
+    <rdf:type rdf:resource="http://example.org/A"/>
+    <rdf:type rdf:resource="http://example.org/B"/>
+  </rdf:Description>
+  <rdf:Description rdf:nodeID="b0">
+    <foaf:name>Carol</foaf:name>
+  </rdf:Description>
+</rdf:RDF>
+`, serialized)
+
+	parsed, err := ParseRDFXML(serialized.(string))
+	require.Nil(t, err)
+	assert.Equal(t, canonicalNQuads(t, dataset), canonicalNQuads(t, parsed))
+}
+
+func TestSerializeRDFXMLCycles(t *testing.T) {
+	dataset, err := ParseTurtle(`<http://example.org/a> <http://example.org/p> _:x .
+_:x <http://example.org/p> _:y .
+_:y <http://example.org/p> _:x .
+_:z <http://example.org/p> _:z .
+`)
+	require.Nil(t, err)
+
+	serialized, err := (&RDFXMLRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+	parsed, err := ParseRDFXML(serialized.(string))
+	require.Nil(t, err)
+	assert.Equal(t, canonicalNQuads(t, dataset), canonicalNQuads(t, parsed))
+}
+
+func TestSerializeRDFXMLErrors(t *testing.T) {
+	a := NewIRI("http://example.org/a")
+	p := NewIRI("http://example.org/p")
+	quads := []*Quad{
+		// no NCName at the end of the predicate
+		NewQuad(a, NewIRI("http://example.org/1"), NewLiteral("x", XSDString, ""), "@default"),
+		// rdf:li would be read back as rdf:_1
+		NewQuad(a, NewIRI(RDFSyntaxNS+"li"), NewLiteral("x", XSDString, ""), "@default"),
+		// characters which XML can't represent
+		NewQuad(a, p, NewLiteral("bell\x07", XSDString, ""), "@default"),
+		NewQuad(a, p, NewIRI("http://example.org/\x07"), "@default"),
+		NewQuad(NewIRI("http://example.org/\x07"), p, a, "@default"),
+		NewQuad(a, NewIRI("http://example.org/\x07/p"), a, "@default"),
+	}
+	for _, quad := range quads {
+		dataset := NewRDFDataset()
+		dataset.Graphs["@default"] = []*Quad{quad}
+		_, err := (&RDFXMLRDFSerializer{}).Serialize(dataset)
+		if assert.NotNil(t, err, quad) {
+			assert.Equal(t, InvalidInput, err.(*JsonLdError).Code, quad)
+		}
+	}
+}
+
+func TestToRDFXML(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.Format = "application/rdf+xml"
+	opts.UseNamespaces = true
+
+	doc := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"foaf": "http://xmlns.com/foaf/0.1/",
+		},
+		"@id":       "http://example.org/alice",
+		"@type":     "foaf:Person",
+		"foaf:name": "Alice",
+	}
+	rdfxml, err := proc.ToRDF(doc, opts)
+	require.Nil(t, err)
+
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF
+  xmlns:foaf="http://xmlns.com/foaf/0.1/"
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <foaf:Person rdf:about="http://example.org/alice">
+    <foaf:name>Alice</foaf:name>
+  </foaf:Person>
+</rdf:RDF>
+`, rdfxml)
+}
+
+func TestSerializeRDFXMLTypes(t *testing.T) {
+	dataset := NewRDFDataset()
+	b := NewIRI("http://example.org/b")
+	dataset.Graphs["@default"] = []*Quad{
+		NewQuad(NewIRI("http://example.org/a"), NewIRI(RDFType), NewIRI(RDFDescription), "@default"),
+		// the foo prefix isn't declared in the first literal
+		NewQuad(b, NewIRI("http://example.org/p"), NewLiteral("<foo:b>hi</foo:b>", RDFXMLLiteral, ""), "@default"),
+		NewQuad(b, NewIRI("http://example.org/q"),
+			NewLiteral(`<foo:b xmlns:foo="http://example.org/foo#">hi</foo:b>`, RDFXMLLiteral, ""), "@default"),
+	}
+
+	serialized, err := (&RDFXMLRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<rdf:RDF
+  xmlns:ns0="http://example.org/"
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="http://example.org/a">
+    <rdf:type rdf:resource="http://www.w3.org/1999/02/22-rdf-syntax-ns#Description"/>
+  </rdf:Description>
+  <rdf:Description rdf:about="http://example.org/b">
+    <ns0:p rdf:datatype="http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral">&lt;foo:b&gt;hi&lt;/foo:b&gt;</ns0:p>
+    <ns0:q rdf:parseType="Literal"><foo:b xmlns:foo="http://example.org/foo#">hi</foo:b></ns0:q>
+  </rdf:Description>
+</rdf:RDF>
+`, serialized)
+
+	parsed, err := ParseRDFXML(serialized.(string))
+	require.Nil(t, err)
+	assert.Equal(t, canonicalNQuads(t, dataset), canonicalNQuads(t, parsed))
+}
+
+func TestFromRDFXMLBase(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("http://example.org/doc.rdf")
+	opts.Format = "application/rdf+xml"
+
+	doc, err := proc.FromRDF(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+		xmlns:ex="http://example.org/">
+		<rdf:Description rdf:about="">
+			<ex:part rdf:resource="#part"/>
+		</rdf:Description>
+		<rdf:Description rdf:ID="part" ex:name="Part"/>
+	</rdf:RDF>`, opts)
+	require.Nil(t, err)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"@id": "http://example.org/doc.rdf",
+			"http://example.org/part": []interface{}{
+				map[string]interface{}{"@id": "http://example.org/doc.rdf#part"},
+			},
+		},
+		map[string]interface{}{
+			"@id": "http://example.org/doc.rdf#part",
+			"http://example.org/name": []interface{}{
+				map[string]interface{}{"@value": "Part"},
+			},
+		},
+	}, doc)
+}
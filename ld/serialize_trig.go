@@ -0,0 +1,115 @@
+package ld
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+)
+
+// TriGRDFSerializer parses and serializes TriG data.
+type TriGRDFSerializer struct {
+}
+
+// Parse TriG from io.Reader, []byte or string into an RDFDataset.
+// The prefixes declared in the document are set as namespaces of the dataset.
+func (s *TriGRDFSerializer) Parse(input interface{}) (*RDFDataset, error) {
+	return ParseTriGFrom(input)
+}
+
+// SerializeTo writes RDFDataset as TriG into a writer.
+// The namespaces of the dataset are used as prefixes.
+func (s *TriGRDFSerializer) SerializeTo(w io.Writer, dataset *RDFDataset) error {
+	tw := newTurtleWriter(dataset)
+
+	graphNames := make([]string, 0, len(dataset.Graphs))
+	for graphName := range dataset.Graphs {
+		if graphName != "@default" {
+			graphNames = append(graphNames, graphName)
+		}
+	}
+	// IRIs first, then blank nodes
+	sort.Slice(graphNames, func(i, j int) bool {
+		bi, bj := strings.HasPrefix(graphNames[i], "_:"), strings.HasPrefix(graphNames[j], "_:")
+		if bi != bj {
+			return bj
+		}
+		return graphNames[i] < graphNames[j]
+	})
+
+	// blank nodes are shared between the graphs of a dataset: they can only be
+	// inlined if they are used in a single graph and aren't graph names
+	graphsOfBlankNode := make(map[string]map[string]bool)
+	for graphName, quads := range dataset.Graphs {
+		if strings.HasPrefix(graphName, "_:") {
+			tw.keepLabels[graphName] = true
+		}
+		for _, quad := range quads {
+			for _, node := range []Node{quad.Subject, quad.Object} {
+				if !IsBlankNode(node) {
+					continue
+				}
+				label := node.GetValue()
+				if graphsOfBlankNode[label] == nil {
+					graphsOfBlankNode[label] = make(map[string]bool)
+				}
+				graphsOfBlankNode[label][graphName] = true
+			}
+		}
+	}
+	for label, graphs := range graphsOfBlankNode {
+		if len(graphs) > 1 {
+			tw.keepLabels[label] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(tw.graphToTurtle(dataset.GetQuads("@default"), ""))
+	for _, graphName := range graphNames {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		var label string
+		if strings.HasPrefix(graphName, "_:") {
+			label = graphName
+		} else {
+			label = tw.iriToTurtle(graphName)
+		}
+		buf.WriteString(label + " {\n" + tw.graphToTurtle(dataset.GetQuads(graphName), "    ") + "}\n")
+	}
+
+	if _, err := io.WriteString(w, tw.prefixesToTurtle()+buf.String()); err != nil {
+		return NewJsonLdError(IOError, err)
+	}
+	return nil
+}
+
+// Serialize an RDFDataset into a TriG string.
+func (s *TriGRDFSerializer) Serialize(dataset *RDFDataset) (interface{}, error) {
+	buf := bytes.NewBuffer(nil)
+	if err := s.SerializeTo(buf, dataset); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// ParseTriGFrom parses RDF in the form of TriG from io.Reader, []byte or string.
+// See https://www.w3.org/TR/trig/
+func ParseTriGFrom(o interface{}) (*RDFDataset, error) {
+	input, err := readRDFInput(o)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newTurtleParser(input)
+	p.trig = true
+	if err := p.parseDocument(); err != nil {
+		return nil, err
+	}
+	return p.dataset, nil
+}
+
+// ParseTriG parses RDF in the form of TriG.
+func ParseTriG(input string) (*RDFDataset, error) {
+	return ParseTriGFrom(input)
+}
@@ -0,0 +1,136 @@
+package ld_test
+
+import (
+	"testing"
+
+	. "github.com/kazarena/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const trigInput = `@prefix ex: <http://example.org/> .
+PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+
+ex:alice foaf:name "Alice" .
+
+ex:people {
+	ex:alice foaf:knows ex:bob, [ foaf:name "Carol" ] .
+	ex:bob foaf:name "Bob"
+}
+
+GRAPH _:provenance {
+	ex:people ex:source _:shared ; ex:checked true .
+}
+
+_:shared ex:label "shared" .
+
+{ ex:dave foaf:name "Dave" }
+`
+
+// canonicalNQuads returns the N-Quads of the URDNA2015 normalized dataset,
+// which don't depend on the labels of blank nodes.
+func canonicalNQuads(t *testing.T, dataset *RDFDataset) string {
+	opts := NewJsonLdOptions("")
+	opts.Algorithm = "URDNA2015"
+	opts.Format = "application/nquads"
+	nquads, err := NewJsonLdApi().Normalize(dataset, opts)
+	require.Nil(t, err)
+	return nquads.(string)
+}
+
+func TestParseTriG(t *testing.T) {
+	dataset, err := ParseTriG(trigInput)
+	require.Nil(t, err)
+
+	nquads, err := (&NQuadRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+	assert.Equal(t, `<http://example.org/alice> <http://xmlns.com/foaf/0.1/knows> <http://example.org/bob> <http://example.org/people> .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/knows> _:b0 <http://example.org/people> .
+<http://example.org/alice> <http://xmlns.com/foaf/0.1/name> "Alice" .
+<http://example.org/bob> <http://xmlns.com/foaf/0.1/name> "Bob" <http://example.org/people> .
+<http://example.org/dave> <http://xmlns.com/foaf/0.1/name> "Dave" .
+<http://example.org/people> <http://example.org/checked> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> _:b1 .
+<http://example.org/people> <http://example.org/source> _:b2 _:b1 .
+_:b0 <http://xmlns.com/foaf/0.1/name> "Carol" <http://example.org/people> .
+_:b2 <http://example.org/label> "shared" .
+`, nquads)
+
+	assert.Equal(t, map[string]string{
+		"ex":   "http://example.org/",
+		"foaf": "http://xmlns.com/foaf/0.1/",
+	}, dataset.GetNamespaces())
+}
+
+func TestParseTriGErrors(t *testing.T) {
+	inputs := []string{
+		`<http://example.org/g> { <http://example.org/s> <http://example.org/p> <http://example.org/o> .`,
+		`GRAPH <http://example.org/g> <http://example.org/s> <http://example.org/p> <http://example.org/o> .`,
+		`{ <http://example.org/g> { <http://example.org/s> <http://example.org/p> <http://example.org/o> } }`,
+		`"literal" { <http://example.org/s> <http://example.org/p> <http://example.org/o> }`,
+	}
+	for _, input := range inputs {
+		_, err := ParseTriG(input)
+		if assert.NotNil(t, err, input) {
+			assert.Equal(t, SyntaxError, err.(*JsonLdError).Code, input)
+		}
+	}
+}
+
+func TestSerializeTriG(t *testing.T) {
+	dataset, err := ParseTriG(trigInput)
+	require.Nil(t, err)
+
+	trig, err := (&TriGRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+
+	assert.Equal(t, `@prefix ex: <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+ex:alice foaf:name "Alice" .
+
+ex:dave foaf:name "Dave" .
+
+_:b2 ex:label "shared" .
+
+ex:people {
+    ex:alice foaf:knows ex:bob, [
+            foaf:name "Carol"
+        ] .
+
+    ex:bob foaf:name "Bob" .
+}
+
+_:b1 {
+    ex:people ex:checked true ;
+        ex:source _:b2 .
+}
+`, trig)
+
+	// the TriG document describes the same dataset
+	reparsed, err := ParseTriG(trig.(string))
+	require.Nil(t, err)
+	assert.Equal(t, canonicalNQuads(t, dataset), canonicalNQuads(t, reparsed))
+}
+
+func TestFromRDFTriG(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.Format = "application/trig"
+
+	doc, err := proc.FromRDF(`<http://example.org/g> {
+		<http://example.org/s> <http://example.org/p> "o" .
+	}`, opts)
+	require.Nil(t, err)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"@id": "http://example.org/g",
+			"@graph": []interface{}{
+				map[string]interface{}{
+					"@id":                  "http://example.org/s",
+					"http://example.org/p": []interface{}{map[string]interface{}{"@value": "o"}},
+				},
+			},
+		},
+	}, doc)
+}
@@ -1,15 +1,1312 @@
 package ld
 
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
 // TurtleRDFSerializer parses and serializes Turtle data.
 type TurtleRDFSerializer struct {
 }
 
-// Parse Turtle from string into an RDFDataset
+// Parse Turtle from io.Reader, []byte or string into an RDFDataset.
+// The prefixes declared in the document are set as namespaces of the dataset.
 func (s *TurtleRDFSerializer) Parse(input interface{}) (*RDFDataset, error) {
-	return nil, NewJsonLdError(NotImplemented, "Turtle not supported")
+	return ParseTurtleFrom(input)
+}
+
+// SerializeTo writes the default graph of RDFDataset as Turtle into a writer.
+// The namespaces of the dataset are used as prefixes.
+func (s *TurtleRDFSerializer) SerializeTo(w io.Writer, dataset *RDFDataset) error {
+	tw := newTurtleWriter(dataset)
+	body := tw.graphToTurtle(dataset.GetQuads("@default"), "")
+	if _, err := io.WriteString(w, tw.prefixesToTurtle()+body); err != nil {
+		return NewJsonLdError(IOError, err)
+	}
+	return nil
 }
 
-// Serialize an RDFDataset into a Turtle string.
+// Serialize the default graph of an RDFDataset into a Turtle string.
 func (s *TurtleRDFSerializer) Serialize(dataset *RDFDataset) (interface{}, error) {
-	return nil, NewJsonLdError(NotImplemented, "Turtle not supported")
+	buf := bytes.NewBuffer(nil)
+	if err := s.SerializeTo(buf, dataset); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// ParseTurtleFrom parses RDF in the form of Turtle from io.Reader, []byte or string.
+// See https://www.w3.org/TR/turtle/
+func ParseTurtleFrom(o interface{}) (*RDFDataset, error) {
+	input, err := readRDFInput(o)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newTurtleParser(input)
+	if err := p.parseDocument(); err != nil {
+		return nil, err
+	}
+	return p.dataset, nil
+}
+
+// ParseTurtle parses RDF in the form of Turtle.
+func ParseTurtle(input string) (*RDFDataset, error) {
+	return ParseTurtleFrom(input)
+}
+
+// readRDFInput reads the contents of an io.Reader, []byte or string.
+func readRDFInput(o interface{}) (string, error) {
+	switch inp := o.(type) {
+	case []byte:
+		return string(inp), nil
+	case string:
+		return inp, nil
+	case io.Reader:
+		b, err := ioutil.ReadAll(inp)
+		if err != nil {
+			return "", NewJsonLdError(IOError, err)
+		}
+		return string(b), nil
+	default:
+		return "", NewJsonLdError(InvalidInput, "expected []byte, string or io.Reader")
+	}
+}
+
+// turtleParser is a recursive descent parser of the Turtle grammar.
+type turtleParser struct {
+	input []rune
+	pos   int
+	line  int
+
+	base     string
+	prefixes map[string]string
+	issuer   *IdentifierIssuer
+
+	// trig enables the TriG graph statements
+	trig bool
+
+	dataset *RDFDataset
+	// graph is the name of the graph triples are added to
+	graph string
+	// seen holds the quads of each graph, to add each of them only once
+	seen map[string]map[string]bool
+}
+
+func newTurtleParser(input string) *turtleParser {
+	return &turtleParser{
+		input:    []rune(input),
+		line:     1,
+		prefixes: make(map[string]string),
+		issuer:   NewIdentifierIssuer("_:b"),
+		dataset:  NewRDFDataset(),
+		graph:    "@default",
+		seen:     make(map[string]map[string]bool),
+	}
+}
+
+// errorf returns a syntax error for the current line.
+func (p *turtleParser) errorf(format string, args ...interface{}) error {
+	syntax := "Turtle"
+	if p.trig {
+		syntax = "TriG"
+	}
+	return NewJsonLdError(SyntaxError, fmt.Errorf("Error while parsing %s; %s. line: %d",
+		syntax, fmt.Sprintf(format, args...), p.line))
+}
+
+// eof returns true if the whole input has been consumed.
+func (p *turtleParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+// peek returns the rune at the given offset from the current position, or 0 past the end of input.
+func (p *turtleParser) peek(offset int) rune {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+// next consumes and returns the current rune.
+func (p *turtleParser) next() rune {
+	r := p.input[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+// hasPrefix returns true if the input continues with s.
+func (p *turtleParser) hasPrefix(s string) bool {
+	i := 0
+	for _, r := range s {
+		if p.peek(i) != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// hasKeyword returns true if the input continues with the given keyword
+// (case-insensitive if required) which isn't part of a longer name.
+func (p *turtleParser) hasKeyword(keyword string, caseInsensitive bool) bool {
+	i := 0
+	for _, r := range keyword {
+		c := p.peek(i)
+		if c != r && (!caseInsensitive || strings.ToLower(string(c)) != strings.ToLower(string(r))) {
+			return false
+		}
+		i++
+	}
+	return p.isNameEnd(i)
+}
+
+// isNameEnd returns true if the name being scanned can't continue at the given offset.
+func (p *turtleParser) isNameEnd(offset int) bool {
+	for p.peek(offset) == '.' {
+		offset++
+	}
+	next := p.peek(offset)
+	return !isPNChars(next) && next != ':'
+}
+
+// expect consumes the given string after any whitespace or raises an error.
+func (p *turtleParser) expect(s string) error {
+	p.skipWS()
+	if !p.hasPrefix(s) {
+		return p.errorf("expected '%s'", s)
+	}
+	p.pos += utf8.RuneCountInString(s)
+	return nil
+}
+
+// skipWS skips whitespace and comments.
+func (p *turtleParser) skipWS() {
+	for !p.eof() {
+		switch p.peek(0) {
+		case ' ', '\t', '\r', '\n':
+			p.next()
+		case '#':
+			for !p.eof() && p.peek(0) != '\n' && p.peek(0) != '\r' {
+				p.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// emit adds a triple to the current graph.
+func (p *turtleParser) emit(subject Node, predicate Node, object Node) {
+	quad := NewQuad(subject, predicate, object, p.graph)
+	seen, hasGraph := p.seen[p.graph]
+	if !hasGraph {
+		seen = make(map[string]bool)
+		p.seen[p.graph] = seen
+	}
+	key := toNQuad(quad, "")
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+	p.dataset.Graphs[p.graph] = append(p.dataset.Graphs[p.graph], quad)
+}
+
+// parseDocument parses a whole Turtle document.
+func (p *turtleParser) parseDocument() error {
+	for {
+		p.skipWS()
+		if p.eof() {
+			return nil
+		}
+		if err := p.parseStatement(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseStatement parses a directive or a triples statement.
+func (p *turtleParser) parseStatement() error {
+	if isDirective, err := p.parseDirective(); isDirective || err != nil {
+		return err
+	}
+	if p.trig {
+		if isGraph, err := p.parseGraphStatement(); isGraph || err != nil {
+			return err
+		}
+	}
+	if err := p.parseTriples(); err != nil {
+		return err
+	}
+	return p.expect(".")
+}
+
+// parseDirective parses @prefix, @base, PREFIX and BASE directives.
+// It returns false if the input doesn't continue with a directive.
+func (p *turtleParser) parseDirective() (bool, error) {
+	switch {
+	case p.hasKeyword("@prefix", false):
+		p.pos += len("@prefix")
+		if err := p.parsePrefixID(); err != nil {
+			return true, err
+		}
+		return true, p.expect(".")
+	case p.hasKeyword("@base", false):
+		p.pos += len("@base")
+		if err := p.parseBase(); err != nil {
+			return true, err
+		}
+		return true, p.expect(".")
+	case p.hasKeyword("PREFIX", true):
+		p.pos += len("PREFIX")
+		return true, p.parsePrefixID()
+	case p.hasKeyword("BASE", true):
+		p.pos += len("BASE")
+		return true, p.parseBase()
+	}
+	return false, nil
+}
+
+// parseGraphStatement parses the TriG statements starting with a graph name or a subject:
+// wrapped graphs (with an optional GRAPH keyword and label) and triples whose subject is
+// an IRI or a blank node. It returns false if the input continues with other triples.
+func (p *turtleParser) parseGraphStatement() (bool, error) {
+	p.skipWS()
+	if p.peek(0) == '{' {
+		return true, p.parseWrappedGraph("@default")
+	}
+	graphKeyword := p.hasKeyword("GRAPH", true)
+	if graphKeyword {
+		p.pos += len("GRAPH")
+		p.skipWS()
+	} else if p.peek(0) == '(' || (p.peek(0) == '[' && !p.isAnon()) {
+		return false, nil
+	}
+
+	// labelOrSubject
+	var label Node
+	var err error
+	if p.peek(0) == '[' {
+		label, err = p.parseBlankNodePropertyList()
+	} else if p.peek(0) == '_' {
+		label, err = p.parseBlankNodeLabel()
+	} else {
+		label, err = p.parseIRI()
+	}
+	if err != nil {
+		return true, err
+	}
+
+	p.skipWS()
+	if p.peek(0) == '{' {
+		return true, p.parseWrappedGraph(label.GetValue())
+	}
+	if graphKeyword {
+		return true, p.errorf("expected '{'")
+	}
+	if err := p.parsePredicateObjectList(label); err != nil {
+		return true, err
+	}
+	return true, p.expect(".")
+}
+
+// parseWrappedGraph parses the triples of a graph enclosed in curly braces.
+func (p *turtleParser) parseWrappedGraph(graph string) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	p.graph = graph
+	defer func() {
+		p.graph = "@default"
+	}()
+	for {
+		p.skipWS()
+		if p.peek(0) == '}' {
+			p.next()
+			return nil
+		}
+		if err := p.parseTriples(); err != nil {
+			return err
+		}
+		p.skipWS()
+		if p.peek(0) != '.' {
+			return p.expect("}")
+		}
+		p.next()
+	}
+}
+
+// parsePrefixID parses the prefix name and IRI of a prefix directive.
+func (p *turtleParser) parsePrefixID() error {
+	p.skipWS()
+	prefix := p.scanPrefix()
+	if p.peek(0) != ':' {
+		return p.errorf("invalid prefix name")
+	}
+	p.next()
+	p.skipWS()
+	iri, err := p.parseIRIRef()
+	if err != nil {
+		return err
+	}
+	p.prefixes[prefix] = iri
+	p.dataset.SetNamespace(prefix, iri)
+	return nil
+}
+
+// parseBase parses the IRI of a base directive.
+func (p *turtleParser) parseBase() error {
+	p.skipWS()
+	iri, err := p.parseIRIRef()
+	if err != nil {
+		return err
+	}
+	p.base = iri
+	return nil
+}
+
+// parseTriples parses the subject and predicate object list of a triples statement.
+func (p *turtleParser) parseTriples() error {
+	p.skipWS()
+	var subject Node
+	var err error
+	if p.peek(0) == '[' && !p.isAnon() {
+		// a blank node property list may be followed by a predicate object list
+		if subject, err = p.parseBlankNodePropertyList(); err != nil {
+			return err
+		}
+		p.skipWS()
+		if p.peek(0) == '.' || p.peek(0) == '}' || p.eof() {
+			return nil
+		}
+	} else if subject, err = p.parseSubject(); err != nil {
+		return err
+	}
+	return p.parsePredicateObjectList(subject)
+}
+
+// parseSubject parses an IRI, blank node or collection subject.
+func (p *turtleParser) parseSubject() (Node, error) {
+	p.skipWS()
+	switch p.peek(0) {
+	case '[':
+		return p.parseBlankNodePropertyList()
+	case '(':
+		return p.parseCollection()
+	case '_':
+		return p.parseBlankNodeLabel()
+	default:
+		if p.isLiteralStart() {
+			return nil, p.errorf("a literal can't be used as a subject")
+		}
+		return p.parseIRI()
+	}
+}
+
+// parsePredicateObjectList parses predicate object lists separated by ';'.
+func (p *turtleParser) parsePredicateObjectList(subject Node) error {
+	for {
+		predicate, err := p.parseVerb()
+		if err != nil {
+			return err
+		}
+		if err := p.parseObjectList(subject, predicate); err != nil {
+			return err
+		}
+
+		p.skipWS()
+		if p.peek(0) != ';' {
+			return nil
+		}
+		for p.peek(0) == ';' {
+			p.next()
+			p.skipWS()
+		}
+		switch p.peek(0) {
+		case '.', ']', '}', 0:
+			return nil
+		}
+	}
+}
+
+// parseVerb parses a predicate IRI or 'a'.
+func (p *turtleParser) parseVerb() (Node, error) {
+	p.skipWS()
+	if p.hasKeyword("a", false) {
+		p.next()
+		return NewIRI(RDFType), nil
+	}
+	return p.parseIRI()
+}
+
+// parseObjectList parses objects separated by ',' and emits their triples.
+func (p *turtleParser) parseObjectList(subject Node, predicate Node) error {
+	for {
+		object, err := p.parseObject()
+		if err != nil {
+			return err
+		}
+		p.emit(subject, predicate, object)
+
+		p.skipWS()
+		if p.peek(0) != ',' {
+			return nil
+		}
+		p.next()
+	}
+}
+
+// parseObject parses an IRI, blank node, collection, blank node property list or literal.
+func (p *turtleParser) parseObject() (Node, error) {
+	p.skipWS()
+	switch c := p.peek(0); {
+	case c == '[':
+		return p.parseBlankNodePropertyList()
+	case c == '(':
+		return p.parseCollection()
+	case c == '_' && p.peek(1) == ':':
+		return p.parseBlankNodeLabel()
+	case c == '"' || c == '\'':
+		return p.parseRDFLiteral()
+	case c == '+' || c == '-' || (c == '.' && isDigit(p.peek(1))) || isDigit(c):
+		return p.parseNumericLiteral()
+	case p.hasKeyword("true", false) || p.hasKeyword("false", false):
+		value := "true"
+		if c == 'f' {
+			value = "false"
+		}
+		p.pos += len(value)
+		return NewLiteral(value, XSDBoolean, ""), nil
+	default:
+		return p.parseIRI()
+	}
+}
+
+// isLiteralStart returns true if the input continues with a literal.
+func (p *turtleParser) isLiteralStart() bool {
+	c := p.peek(0)
+	return c == '"' || c == '\'' || c == '+' || c == '-' || (c == '.' && isDigit(p.peek(1))) || isDigit(c) ||
+		p.hasKeyword("true", false) || p.hasKeyword("false", false)
+}
+
+// isAnon returns true if the input continues with an anonymous blank node ('[' WS* ']').
+func (p *turtleParser) isAnon() bool {
+	i := 1
+	for {
+		switch p.peek(i) {
+		case ' ', '\t', '\r', '\n':
+			i++
+		case '#':
+			for c := p.peek(i); c != 0 && c != '\n' && c != '\r'; c = p.peek(i) {
+				i++
+			}
+		case ']':
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// parseBlankNodePropertyList parses '[' predicateObjectList ']' or an anonymous blank node.
+func (p *turtleParser) parseBlankNodePropertyList() (Node, error) {
+	anon := p.isAnon()
+	p.next()
+	node := NewBlankNode(p.issuer.GetId(""))
+	if !anon {
+		if err := p.parsePredicateObjectList(node); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// parseCollection parses '(' object* ')' into an RDF list.
+func (p *turtleParser) parseCollection() (Node, error) {
+	p.next()
+	var head, last Node
+	head = NewIRI(RDFNil)
+	for {
+		p.skipWS()
+		if p.eof() {
+			return nil, p.errorf("unterminated collection")
+		}
+		if p.peek(0) == ')' {
+			p.next()
+			break
+		}
+		// the node of the list item must be created before the nodes of the object
+		node := NewBlankNode(p.issuer.GetId(""))
+		object, err := p.parseObject()
+		if err != nil {
+			return nil, err
+		}
+		if last == nil {
+			head = node
+		} else {
+			p.emit(last, NewIRI(RDFRest), node)
+		}
+		p.emit(node, NewIRI(RDFFirst), object)
+		last = node
+	}
+	if last != nil {
+		p.emit(last, NewIRI(RDFRest), NewIRI(RDFNil))
+	}
+	return head, nil
+}
+
+// parseBlankNodeLabel parses a labelled blank node (_:label).
+func (p *turtleParser) parseBlankNodeLabel() (Node, error) {
+	if !p.hasPrefix("_:") {
+		return nil, p.errorf("invalid blank node")
+	}
+	p.pos += 2
+	start := p.pos
+	if c := p.peek(0); !isPNCharsU(c) && !(c >= '0' && c <= '9') {
+		return nil, p.errorf("invalid blank node label")
+	}
+	p.next()
+	p.scanName(false)
+	label := string(p.input[start:p.pos])
+	return NewBlankNode(p.issuer.GetId("_:" + label)), nil
+}
+
+// parseIRI parses an IRI reference or a prefixed name.
+func (p *turtleParser) parseIRI() (Node, error) {
+	p.skipWS()
+	if p.peek(0) == '<' {
+		iri, err := p.parseIRIRef()
+		if err != nil {
+			return nil, err
+		}
+		return NewIRI(iri), nil
+	}
+	if p.eof() {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	prefix := p.scanPrefix()
+	if p.peek(0) != ':' {
+		return nil, p.errorf("unexpected '%c'", p.peek(0))
+	}
+	p.next()
+	ns, hasPrefix := p.prefixes[prefix]
+	if !hasPrefix {
+		return nil, p.errorf("undefined prefix '%s'", prefix)
+	}
+	local, err := p.scanLocalName()
+	if err != nil {
+		return nil, err
+	}
+	return NewIRI(ns + local), nil
+}
+
+// parseIRIRef parses an IRI enclosed in angle brackets and resolves it against the base IRI.
+func (p *turtleParser) parseIRIRef() (string, error) {
+	if p.peek(0) != '<' {
+		return "", p.errorf("expected IRI")
+	}
+	p.next()
+	var buf bytes.Buffer
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated IRI")
+		}
+		c := p.next()
+		switch {
+		case c == '>':
+			return resolveIRIRef(p.base, buf.String()), nil
+		case c == '\\':
+			r, err := p.parseUChar()
+			if err != nil {
+				return "", err
+			}
+			if r <= 0x20 || strings.ContainsRune("<>\"{}|^`\\", r) {
+				return "", p.errorf("invalid character in IRI")
+			}
+			buf.WriteRune(r)
+		case c <= 0x20 || strings.ContainsRune("<\"{}|^`", c):
+			return "", p.errorf("invalid character in IRI")
+		default:
+			buf.WriteRune(c)
+		}
+	}
+}
+
+var rAbsoluteIRI = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9+.-]*:")
+
+// resolveIRIRef resolves an IRI reference against the base IRI, leaving absolute IRIs unchanged.
+func resolveIRIRef(base string, iri string) string {
+	if base == "" || rAbsoluteIRI.MatchString(iri) {
+		return iri
+	}
+	if iri == "" {
+		if i := strings.Index(base, "#"); i >= 0 {
+			return base[:i]
+		}
+		return base
+	}
+	return Resolve(base, iri)
+}
+
+// parseUChar parses a \u or \U escape sequence, the backslash being already consumed.
+func (p *turtleParser) parseUChar() (rune, error) {
+	size := 0
+	switch p.peek(0) {
+	case 'u':
+		size = 4
+	case 'U':
+		size = 8
+	default:
+		return 0, p.errorf("invalid escape sequence")
+	}
+	p.next()
+	if p.pos+size > len(p.input) {
+		return 0, p.errorf("invalid escape sequence")
+	}
+	code, err := strconv.ParseUint(string(p.input[p.pos:p.pos+size]), 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid escape sequence")
+	}
+	p.pos += size
+	return rune(code), nil
+}
+
+// scanPrefix scans an optional prefix name (PN_PREFIX) up to the colon.
+func (p *turtleParser) scanPrefix() string {
+	start := p.pos
+	if isPNCharsBase(p.peek(0)) {
+		p.next()
+		p.scanName(false)
+	}
+	return string(p.input[start:p.pos])
+}
+
+// scanName scans the rest of a name made of PN_CHARS and dots which don't end it.
+// If local is true, colons and the escapes of local names are scanned as well.
+func (p *turtleParser) scanName(local bool) {
+	for !p.eof() {
+		c := p.peek(0)
+		switch {
+		case isPNChars(c):
+			p.next()
+		case local && (c == ':' || c == '%' || c == '\\'):
+			return
+		case c == '.':
+			// dots can't end a name
+			i := 1
+			for p.peek(i) == '.' {
+				i++
+			}
+			next := p.peek(i)
+			if !isPNChars(next) && !(local && (next == ':' || next == '%' || next == '\\')) {
+				return
+			}
+			p.pos += i
+		default:
+			return
+		}
+	}
+}
+
+// scanLocalName scans the local part of a prefixed name (PN_LOCAL), unescaping it.
+func (p *turtleParser) scanLocalName() (string, error) {
+	var buf bytes.Buffer
+	first := true
+	for !p.eof() {
+		c := p.peek(0)
+		switch {
+		case c == ':' || isPNChars(c) && (!first || isPNCharsU(c) || (c >= '0' && c <= '9')):
+			start := p.pos
+			p.next()
+			p.scanName(true)
+			buf.WriteString(string(p.input[start:p.pos]))
+		case c == '%':
+			if !isHex(p.peek(1)) || !isHex(p.peek(2)) {
+				return "", p.errorf("invalid percent encoding in local name")
+			}
+			buf.WriteString(string(p.input[p.pos : p.pos+3]))
+			p.pos += 3
+		case c == '\\':
+			if !strings.ContainsRune("_~.-!$&'()*+,;=/?#@%", p.peek(1)) || p.peek(1) == 0 {
+				return "", p.errorf("invalid escape in local name")
+			}
+			buf.WriteRune(p.peek(1))
+			p.pos += 2
+		case c == '.' && !first:
+			// continue the name only if the dots are followed by name characters
+			i := 1
+			for p.peek(i) == '.' {
+				i++
+			}
+			next := p.peek(i)
+			if !isPNChars(next) && next != ':' && next != '%' && next != '\\' {
+				return buf.String(), nil
+			}
+			buf.WriteString(string(p.input[p.pos : p.pos+i]))
+			p.pos += i
+		default:
+			return buf.String(), nil
+		}
+		first = false
+	}
+	return buf.String(), nil
+}
+
+// parseRDFLiteral parses a string, optionally followed by a language tag or a datatype.
+func (p *turtleParser) parseRDFLiteral() (Node, error) {
+	value, err := p.parseString()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek(0) == '@' {
+		p.next()
+		start := p.pos
+		for isASCIILetter(p.peek(0)) {
+			p.next()
+		}
+		if p.pos == start {
+			return nil, p.errorf("invalid language tag")
+		}
+		for p.peek(0) == '-' && (isASCIILetter(p.peek(1)) || isDigit(p.peek(1))) {
+			p.next()
+			for isASCIILetter(p.peek(0)) || isDigit(p.peek(0)) {
+				p.next()
+			}
+		}
+		return NewLiteral(value, RDFLangString, string(p.input[start:p.pos])), nil
+	}
+
+	p.skipWS()
+	if p.hasPrefix("^^") {
+		p.pos += 2
+		datatype, err := p.parseIRI()
+		if err != nil {
+			return nil, err
+		}
+		return NewLiteral(value, datatype.GetValue(), ""), nil
+	}
+	return NewLiteral(value, XSDString, ""), nil
+}
+
+// parseString parses a short or long string quoted with single or double quotes.
+func (p *turtleParser) parseString() (string, error) {
+	quote := p.next()
+	long := p.peek(0) == quote && p.peek(1) == quote
+	if long {
+		p.pos += 2
+	}
+
+	var buf bytes.Buffer
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.next()
+		switch {
+		case c == quote && !long:
+			return buf.String(), nil
+		case c == quote && p.peek(0) == quote && p.peek(1) == quote:
+			// the closing quotes of a long string may be preceded by up to two quotes
+			for p.peek(2) == quote {
+				buf.WriteRune(p.next())
+			}
+			p.pos += 2
+			return buf.String(), nil
+		case c == '\\':
+			switch e := p.peek(0); e {
+			case 't', 'b', 'n', 'r', 'f', '"', '\'', '\\':
+				p.next()
+				buf.WriteRune(turtleEscapes[e])
+			default:
+				r, err := p.parseUChar()
+				if err != nil {
+					return "", err
+				}
+				buf.WriteRune(r)
+			}
+		case !long && (c == '\n' || c == '\r'):
+			return "", p.errorf("line break in a short string")
+		default:
+			buf.WriteRune(c)
+		}
+	}
+}
+
+// turtleEscapes maps the characters of string escape sequences (ECHAR) to their values.
+var turtleEscapes = map[rune]rune{
+	't': '\t', 'b': '\b', 'n': '\n', 'r': '\r', 'f': '\f', '"': '"', '\'': '\'', '\\': '\\',
+}
+
+// parseNumericLiteral parses an integer, decimal or double literal.
+func (p *turtleParser) parseNumericLiteral() (Node, error) {
+	start := p.pos
+	if p.peek(0) == '+' || p.peek(0) == '-' {
+		p.next()
+	}
+	intDigits := p.scanDigits()
+
+	datatype := XSDInteger
+	fracDigits := 0
+	if p.peek(0) == '.' && (isDigit(p.peek(1)) || intDigits > 0 && p.isExponent(1)) {
+		p.next()
+		fracDigits = p.scanDigits()
+		datatype = XSDDecimal
+	}
+	if intDigits == 0 && fracDigits == 0 {
+		return nil, p.errorf("invalid numeric literal")
+	}
+	if p.isExponent(0) {
+		p.next()
+		if p.peek(0) == '+' || p.peek(0) == '-' {
+			p.next()
+		}
+		p.scanDigits()
+		datatype = XSDDouble
+	}
+	return NewLiteral(string(p.input[start:p.pos]), datatype, ""), nil
+}
+
+// isExponent returns true if the input at the given offset continues with an exponent.
+func (p *turtleParser) isExponent(offset int) bool {
+	if c := p.peek(offset); c != 'e' && c != 'E' {
+		return false
+	}
+	if c := p.peek(offset + 1); c == '+' || c == '-' {
+		offset++
+	}
+	return isDigit(p.peek(offset + 1))
+}
+
+// scanDigits scans a sequence of digits and returns its length.
+func (p *turtleParser) scanDigits() int {
+	n := 0
+	for isDigit(p.peek(0)) {
+		p.next()
+		n++
+	}
+	return n
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHex(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isASCIILetter(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isPNCharsBase returns true if c matches PN_CHARS_BASE of the Turtle grammar.
+func isPNCharsBase(c rune) bool {
+	return isASCIILetter(c) ||
+		(c >= 0x00C0 && c <= 0x00D6) || (c >= 0x00D8 && c <= 0x00F6) || (c >= 0x00F8 && c <= 0x02FF) ||
+		(c >= 0x0370 && c <= 0x037D) || (c >= 0x037F && c <= 0x1FFF) || (c >= 0x200C && c <= 0x200D) ||
+		(c >= 0x2070 && c <= 0x218F) || (c >= 0x2C00 && c <= 0x2FEF) || (c >= 0x3001 && c <= 0xD7FF) ||
+		(c >= 0xF900 && c <= 0xFDCF) || (c >= 0xFDF0 && c <= 0xFFFD) || (c >= 0x10000 && c <= 0xEFFFF)
+}
+
+// isPNCharsU returns true if c matches PN_CHARS_U of the Turtle grammar.
+func isPNCharsU(c rune) bool {
+	return isPNCharsBase(c) || c == '_'
+}
+
+// isPNChars returns true if c matches PN_CHARS of the Turtle grammar.
+func isPNChars(c rune) bool {
+	return isPNCharsU(c) || c == '-' || isDigit(c) || c == 0x00B7 ||
+		(c >= 0x0300 && c <= 0x036F) || (c >= 0x203F && c <= 0x2040)
+}
+
+// turtleWriter writes graphs in Turtle, abbreviating IRIs with the namespaces of a dataset,
+// inlining blank nodes which are referenced once and writing well-formed lists as collections.
+type turtleWriter struct {
+	// namespaces holds the [prefix, IRI] pairs usable as prefixes, longest IRI first
+	namespaces [][2]string
+	// usedPrefixes holds the prefixes which were used while writing
+	usedPrefixes map[string]string
+	// keepLabels holds the blank nodes which must be written with their label
+	keepLabels map[string]bool
+}
+
+func newTurtleWriter(dataset *RDFDataset) *turtleWriter {
+	tw := &turtleWriter{
+		namespaces:   make([][2]string, 0),
+		usedPrefixes: make(map[string]string),
+		keepLabels:   make(map[string]bool),
+	}
+	for prefix, iri := range dataset.GetNamespaces() {
+		if isValidPrefix(prefix) && iri != "" {
+			tw.namespaces = append(tw.namespaces, [2]string{prefix, iri})
+		}
+	}
+	sort.Slice(tw.namespaces, func(i, j int) bool {
+		a, b := tw.namespaces[i], tw.namespaces[j]
+		if len(a[1]) != len(b[1]) {
+			return len(a[1]) > len(b[1])
+		}
+		return a[0] < b[0]
+	})
+	return tw
+}
+
+// prefixesToTurtle returns the prefix directives of the prefixes used so far.
+func (tw *turtleWriter) prefixesToTurtle() string {
+	if len(tw.usedPrefixes) == 0 {
+		return ""
+	}
+	prefixes := make([]string, 0, len(tw.usedPrefixes))
+	for prefix := range tw.usedPrefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var buf bytes.Buffer
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&buf, "@prefix %s: %s .\n", prefix, iriRefToTurtle(tw.usedPrefixes[prefix]))
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// turtleSubject holds the properties of a subject in a graph.
+type turtleSubject struct {
+	node       Node
+	properties map[string][]Node
+}
+
+// turtleGraph holds the state of writing a graph.
+type turtleGraph struct {
+	subjects map[string]*turtleSubject
+	// references counts the uses of each blank node as an object
+	references map[string]int
+	// inlined holds the blank nodes which have been written inline
+	inlined map[string]bool
+	// labelled holds the blank nodes which must be written with their label
+	labelled map[string]bool
+}
+
+// graphToTurtle returns the triples of a graph in Turtle, each line starting with indent.
+func (tw *turtleWriter) graphToTurtle(quads []*Quad, indent string) string {
+	g := &turtleGraph{
+		subjects:   make(map[string]*turtleSubject),
+		references: make(map[string]int),
+		inlined:    make(map[string]bool),
+		labelled:   make(map[string]bool),
+	}
+	for label := range tw.keepLabels {
+		g.labelled[label] = true
+	}
+	for _, quad := range quads {
+		key := quad.Subject.GetValue()
+		subject, hasSubject := g.subjects[key]
+		if !hasSubject {
+			subject = &turtleSubject{node: quad.Subject, properties: make(map[string][]Node)}
+			g.subjects[key] = subject
+		}
+		predicate := quad.Predicate.GetValue()
+		subject.properties[predicate] = append(subject.properties[predicate], quad.Object)
+		if IsBlankNode(quad.Object) {
+			g.references[quad.Object.GetValue()]++
+		}
+		if IsBlankNode(quad.Predicate) {
+			g.labelled[predicate] = true
+		}
+	}
+
+	// IRIs first, then blank nodes, which are inlined if they are referenced once
+	keys := make([]string, 0, len(g.subjects))
+	for key := range g.subjects {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		bi, bj := IsBlankNode(g.subjects[keys[i]].node), IsBlankNode(g.subjects[keys[j]].node)
+		if bi != bj {
+			return bj
+		}
+		return keys[i] < keys[j]
+	})
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		if IsBlankNode(g.subjects[key].node) && g.references[key] == 1 && !g.labelled[key] {
+			continue
+		}
+		tw.writeSubject(&buf, g, key, indent)
+	}
+	// blank nodes which are only referenced from within their own inlined nodes (cycles)
+	for _, key := range keys {
+		if IsBlankNode(g.subjects[key].node) && !g.inlined[key] && !g.labelled[key] && g.references[key] == 1 {
+			g.labelled[key] = true
+			tw.writeSubject(&buf, g, key, indent)
+		}
+	}
+	return buf.String()
+}
+
+// writeSubject writes a subject with its predicate object list.
+func (tw *turtleWriter) writeSubject(buf *bytes.Buffer, g *turtleGraph, key string, indent string) {
+	subject := g.subjects[key]
+	var term string
+	if IsBlankNode(subject.node) && g.references[key] == 0 && !g.labelled[key] {
+		term = "[]"
+	} else {
+		term = tw.termToTurtle(g, subject.node, indent)
+	}
+	g.inlined[key] = true
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent + term + " " + tw.predicateObjectsToTurtle(g, subject, indent+"    ") + " .\n")
+}
+
+// predicateObjectsToTurtle returns the predicate object list of a subject,
+// the predicates after the first one starting a new line with indent.
+func (tw *turtleWriter) predicateObjectsToTurtle(g *turtleGraph, subject *turtleSubject, indent string) string {
+	predicates := make([]string, 0, len(subject.properties))
+	for predicate := range subject.properties {
+		predicates = append(predicates, predicate)
+	}
+	sort.Slice(predicates, func(i, j int) bool {
+		if (predicates[i] == RDFType) != (predicates[j] == RDFType) {
+			return predicates[i] == RDFType
+		}
+		return predicates[i] < predicates[j]
+	})
+
+	lines := make([]string, 0, len(predicates))
+	for _, predicate := range predicates {
+		var predicateTerm string
+		if predicate == RDFType {
+			predicateTerm = "a"
+		} else if strings.HasPrefix(predicate, "_:") {
+			predicateTerm = predicate
+		} else {
+			predicateTerm = tw.iriToTurtle(predicate)
+		}
+
+		objects := subject.properties[predicate]
+		sort.SliceStable(objects, func(i, j int) bool {
+			return nodeSortKey(objects[i]) < nodeSortKey(objects[j])
+		})
+		terms := make([]string, 0, len(objects))
+		for _, object := range objects {
+			terms = append(terms, tw.termToTurtle(g, object, indent))
+		}
+		lines = append(lines, predicateTerm+" "+strings.Join(terms, ", "))
+	}
+	return strings.Join(lines, " ;\n"+indent)
+}
+
+// nodeSortKey orders IRIs before literals and blank nodes.
+func nodeSortKey(n Node) string {
+	switch {
+	case IsIRI(n):
+		return "0" + n.GetValue()
+	case IsLiteral(n):
+		return "1" + n.GetValue()
+	default:
+		return "2" + n.GetValue()
+	}
+}
+
+// termToTurtle returns the Turtle representation of a node in a line starting with indent.
+func (tw *turtleWriter) termToTurtle(g *turtleGraph, n Node, indent string) string {
+	switch node := n.(type) {
+	case *IRI:
+		if node.Value == RDFNil {
+			return "()"
+		}
+		return tw.iriToTurtle(node.Value)
+	case *Literal:
+		return tw.literalToTurtle(node)
+	}
+
+	label := n.GetValue()
+	if g.labelled[label] || g.references[label] != 1 || g.inlined[label] {
+		return label
+	}
+	g.inlined[label] = true
+
+	if items, isList := tw.listItems(g, label); isList {
+		terms := make([]string, 0, len(items))
+		for _, item := range items {
+			terms = append(terms, tw.termToTurtle(g, item, indent))
+		}
+		return "( " + strings.Join(terms, " ") + " )"
+	}
+
+	subject, hasSubject := g.subjects[label]
+	if !hasSubject {
+		return "[]"
+	}
+	nested := indent + "    "
+	return "[\n" + nested + tw.predicateObjectsToTurtle(g, subject, nested) + "\n" + indent + "]"
+}
+
+// listItems returns the items of the well-formed list starting at the given blank node:
+// each node of the list has exactly one rdf:first and one rdf:rest, and the nodes after
+// the first one are only referenced by the rdf:rest of the previous node.
+func (tw *turtleWriter) listItems(g *turtleGraph, label string) ([]Node, bool) {
+	items := make([]Node, 0)
+	nodes := make([]string, 0)
+	visited := make(map[string]bool)
+	for {
+		subject, hasSubject := g.subjects[label]
+		if !hasSubject || visited[label] || len(subject.properties) != 2 ||
+			len(subject.properties[RDFFirst]) != 1 || len(subject.properties[RDFRest]) != 1 {
+			return nil, false
+		}
+		visited[label] = true
+		nodes = append(nodes, label)
+		items = append(items, subject.properties[RDFFirst][0])
+
+		rest := subject.properties[RDFRest][0]
+		if IsIRI(rest) && rest.GetValue() == RDFNil {
+			break
+		}
+		label = rest.GetValue()
+		if !IsBlankNode(rest) || g.references[label] != 1 || g.labelled[label] || g.inlined[label] {
+			return nil, false
+		}
+	}
+	for _, node := range nodes {
+		g.inlined[node] = true
+	}
+	return items, true
+}
+
+var rTurtleInteger = regexp.MustCompile(`^[+-]?[0-9]+$`)
+var rTurtleDecimal = regexp.MustCompile(`^[+-]?[0-9]*\.[0-9]+$`)
+var rTurtleDouble = regexp.MustCompile(`^[+-]?(?:[0-9]+\.[0-9]*|\.?[0-9]+)[eE][+-]?[0-9]+$`)
+
+// literalToTurtle returns the Turtle representation of a literal, using the shorthand
+// forms of numbers and booleans when possible.
+func (tw *turtleWriter) literalToTurtle(literal *Literal) string {
+	value := literal.Value
+	switch literal.Datatype {
+	case XSDString:
+		return stringToTurtle(value)
+	case RDFLangString:
+		return stringToTurtle(value) + "@" + literal.Language
+	case XSDInteger:
+		if rTurtleInteger.MatchString(value) {
+			return value
+		}
+	case XSDDecimal:
+		if rTurtleDecimal.MatchString(value) {
+			return value
+		}
+	case XSDDouble:
+		if rTurtleDouble.MatchString(value) {
+			return value
+		}
+	case XSDBoolean:
+		if value == "true" || value == "false" {
+			return value
+		}
+	}
+	return stringToTurtle(value) + "^^" + tw.iriToTurtle(literal.Datatype)
+}
+
+// stringToTurtle quotes a string, using a long string if it spans several lines.
+func stringToTurtle(str string) string {
+	long := strings.Contains(str, "\n")
+	var buf bytes.Buffer
+	runes := []rune(str)
+	for i, c := range runes {
+		switch {
+		case c == '\\':
+			buf.WriteString("\\\\")
+		case c == '"' && long && i < len(runes)-1 && runes[i+1] != '"':
+			// quotes only need to be escaped where they could close a long string
+			buf.WriteRune(c)
+		case c == '"':
+			buf.WriteString("\\\"")
+		case c == '\n' && long:
+			buf.WriteRune(c)
+		case c == '\n':
+			buf.WriteString("\\n")
+		case c == '\r':
+			buf.WriteString("\\r")
+		case c == '\t':
+			buf.WriteString("\\t")
+		case c < 0x20 || c == 0x7F:
+			fmt.Fprintf(&buf, "\\u%04X", c)
+		default:
+			buf.WriteRune(c)
+		}
+	}
+	if long {
+		return `"""` + buf.String() + `"""`
+	}
+	return `"` + buf.String() + `"`
+}
+
+// iriToTurtle returns a prefixed name for the IRI if a namespace matches it,
+// or the IRI enclosed in angle brackets.
+func (tw *turtleWriter) iriToTurtle(iri string) string {
+	for _, ns := range tw.namespaces {
+		if strings.HasPrefix(iri, ns[1]) && isValidLocalName(iri[len(ns[1]):]) {
+			tw.usedPrefixes[ns[0]] = ns[1]
+			return ns[0] + ":" + iri[len(ns[1]):]
+		}
+	}
+	return iriRefToTurtle(iri)
+}
+
+// iriRefToTurtle encloses an IRI in angle brackets, escaping the characters
+// which aren't allowed in IRI references.
+func iriRefToTurtle(iri string) string {
+	var buf bytes.Buffer
+	buf.WriteString("<")
+	for _, c := range iri {
+		if c <= 0x20 || strings.ContainsRune("<>\"{}|^`\\", c) {
+			fmt.Fprintf(&buf, "\\u%04X", c)
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+	buf.WriteString(">")
+	return buf.String()
+}
+
+// isValidPrefix returns true if prefix may be used as a prefix name (PN_PREFIX) in Turtle.
+func isValidPrefix(prefix string) bool {
+	for i, c := range prefix {
+		if (i == 0 && !isPNCharsBase(c)) || (!isPNChars(c) && c != '.') {
+			return false
+		}
+	}
+	return !strings.HasSuffix(prefix, ".")
+}
+
+// isValidLocalName returns true if local may be written as the local part of
+// a prefixed name without escapes.
+func isValidLocalName(local string) bool {
+	for i, c := range local {
+		if i == 0 && !isPNCharsU(c) && !isDigit(c) && c != ':' {
+			return false
+		}
+		if !isPNChars(c) && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return !strings.HasSuffix(local, ".")
 }
@@ -0,0 +1,228 @@
+package ld_test
+
+import (
+	"testing"
+
+	. "github.com/kazarena/json-gold/ld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseTurtleToNQuads(t *testing.T, input string) string {
+	dataset, err := ParseTurtle(input)
+	require.Nil(t, err)
+	nquads, err := (&NQuadRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+	return nquads.(string)
+}
+
+func TestParseTurtle(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "prefixes and base",
+			input: `@prefix ex: <http://example.org/> .
+				PREFIX dc: <http://purl.org/dc/terms/>
+				@base <http://example.org/docs/> .
+				<doc1> dc:creator ex:alice ; a ex:Document .
+				BASE <http://example.com/>
+				<doc2> dc:source <../doc1#part> .`,
+			expected: `<http://example.com/doc2> <http://purl.org/dc/terms/source> <http://example.com/doc1#part> .
+<http://example.org/docs/doc1> <http://purl.org/dc/terms/creator> <http://example.org/alice> .
+<http://example.org/docs/doc1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.org/Document> .
+`,
+		},
+		{
+			name: "predicate and object lists",
+			input: `@prefix : <http://example.org/> .
+				:s :p :o1, :o2 ; :q :o3 ;; .`,
+			expected: `<http://example.org/s> <http://example.org/p> <http://example.org/o1> .
+<http://example.org/s> <http://example.org/p> <http://example.org/o2> .
+<http://example.org/s> <http://example.org/q> <http://example.org/o3> .
+`,
+		},
+		{
+			name: "literals",
+			input: `@prefix : <http://example.org/> .
+				:s :p "a\tb", 'c'@en-GB, "d"^^:dt, 1, -2.5, +.5e-3, true, false .`,
+			expected: `<http://example.org/s> <http://example.org/p> "+.5e-3"^^<http://www.w3.org/2001/XMLSchema#double> .
+<http://example.org/s> <http://example.org/p> "-2.5"^^<http://www.w3.org/2001/XMLSchema#decimal> .
+<http://example.org/s> <http://example.org/p> "1"^^<http://www.w3.org/2001/XMLSchema#integer> .
+<http://example.org/s> <http://example.org/p> "a\tb" .
+<http://example.org/s> <http://example.org/p> "c"@en-GB .
+<http://example.org/s> <http://example.org/p> "d"^^<http://example.org/dt> .
+<http://example.org/s> <http://example.org/p> "false"^^<http://www.w3.org/2001/XMLSchema#boolean> .
+<http://example.org/s> <http://example.org/p> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .
+`,
+		},
+		{
+			name: "long strings",
+			input: `<http://example.org/s> <http://example.org/p> """first "quoted"
+second""", '''it's''', """ends with a quote"""" .`,
+			expected: `<http://example.org/s> <http://example.org/p> "ends with a quote\"" .
+<http://example.org/s> <http://example.org/p> "first \"quoted\"\nsecond" .
+<http://example.org/s> <http://example.org/p> "it's" .
+`,
+		},
+		{
+			name: "blank nodes",
+			input: `@prefix : <http://example.org/> .
+				_:a :knows [ :name "Bob" ] .
+				[ :name "Carol" ] :knows _:a .
+				[] :name "Dave" .`,
+			expected: `_:b0 <http://example.org/knows> _:b1 .
+_:b1 <http://example.org/name> "Bob" .
+_:b2 <http://example.org/knows> _:b0 .
+_:b2 <http://example.org/name> "Carol" .
+_:b3 <http://example.org/name> "Dave" .
+`,
+		},
+		{
+			name: "collections",
+			input: `@prefix : <http://example.org/> .
+				:s :p ( :a ( ) ) ; :q () .`,
+			expected: `<http://example.org/s> <http://example.org/p> _:b0 .
+<http://example.org/s> <http://example.org/q> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+_:b0 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://example.org/a> .
+_:b0 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> _:b1 .
+_:b1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+_:b1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#rest> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .
+`,
+		},
+		{
+			name: "local names",
+			input: `@prefix : <http://example.org/> .
+				:a.b :c\~d :e%20f.`,
+			expected: `<http://example.org/a.b> <http://example.org/c~d> <http://example.org/e%20f> .
+`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, parseTurtleToNQuads(t, test.input), test.name)
+	}
+}
+
+func TestParseTurtleErrors(t *testing.T) {
+	inputs := []string{
+		`:s :p :o .`,
+		`<http://example.org/s> <http://example.org/p> "unterminated .`,
+		`<http://example.org/s> <http://example.org/p> .`,
+		`"literal" <http://example.org/p> <http://example.org/o> .`,
+		`<http://example.org/s> <http://example.org/p> <http://example.org/o>`,
+		`<http://example.org/s p> <http://example.org/p> <http://example.org/o> .`,
+	}
+	for _, input := range inputs {
+		_, err := ParseTurtle(input)
+		if assert.NotNil(t, err, input) {
+			assert.Equal(t, SyntaxError, err.(*JsonLdError).Code, input)
+		}
+	}
+}
+
+func TestFromRDFTurtleNamespaces(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.Format = "text/turtle"
+	opts.OutputForm = "compacted"
+
+	doc, err := proc.FromRDF(`@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+		<http://example.org/alice> foaf:name "Alice" .`, opts)
+	require.Nil(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"@context":  map[string]interface{}{"foaf": "http://xmlns.com/foaf/0.1/"},
+		"@id":       "http://example.org/alice",
+		"foaf:name": "Alice",
+	}, doc)
+}
+
+func TestSerializeTurtle(t *testing.T) {
+	dataset, err := ParseTurtle(`@prefix ex: <http://example.org/> .
+		@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+		@prefix unused: <http://example.com/unused#> .
+		ex:alice a foaf:Person ;
+			foaf:name "Alice", "Alicia"@es ;
+			foaf:knows ex:bob, [ foaf:name "Carol" ; ex:age 42 ] ;
+			ex:favourites ( "tea" 2.5 ) ;
+			ex:note """two
+lines""" ;
+			ex:born "1990-01-01"^^<http://www.w3.org/2001/XMLSchema#date> .
+		ex:bob foaf:knows _:dave .
+		ex:carol foaf:knows _:dave .
+		_:dave foaf:name "Dave" .
+		[] ex:anonymous true .`)
+	require.Nil(t, err)
+
+	turtle, err := (&TurtleRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+
+	assert.Equal(t, `@prefix ex: <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+ex:alice a foaf:Person ;
+    ex:born "1990-01-01"^^<http://www.w3.org/2001/XMLSchema#date> ;
+    ex:favourites ( "tea" 2.5 ) ;
+    ex:note """two
+lines""" ;
+    foaf:knows ex:bob, [
+        ex:age 42 ;
+        foaf:name "Carol"
+    ] ;
+    foaf:name "Alice", "Alicia"@es .
+
+ex:bob foaf:knows _:b3 .
+
+ex:carol foaf:knows _:b3 .
+
+_:b3 foaf:name "Dave" .
+
+[] ex:anonymous true .
+`, turtle)
+
+	// the serialized Turtle describes the same graph
+	reparsed, err := ParseTurtle(turtle.(string))
+	require.Nil(t, err)
+	assert.Equal(t, len(dataset.GetQuads("@default")), len(reparsed.GetQuads("@default")))
+}
+
+func TestSerializeTurtleEscapes(t *testing.T) {
+	dataset := NewRDFDataset()
+	dataset.Graphs["@default"] = []*Quad{
+		NewQuad(NewIRI("http://example.org/a b"), NewIRI("http://example.org/p"),
+			NewLiteral("tab\tquote\"backslash\\", XSDString, ""), ""),
+		NewQuad(NewIRI("http://example.org/a b"), NewIRI("http://example.org/p"),
+			NewLiteral("1.5", XSDInteger, ""), ""),
+	}
+
+	turtle, err := (&TurtleRDFSerializer{}).Serialize(dataset)
+	require.Nil(t, err)
+
+	assert.Equal(t, `<http://example.org/a\u0020b> <http://example.org/p> "1.5"^^<http://www.w3.org/2001/XMLSchema#integer>, "tab\tquote\"backslash\\" .
+`, turtle)
+}
+
+func TestToRDFTurtleNamespaces(t *testing.T) {
+	proc := NewJsonLdProcessor()
+	opts := NewJsonLdOptions("")
+	opts.Format = "text/turtle"
+	opts.UseNamespaces = true
+
+	doc := map[string]interface{}{
+		"@context": map[string]interface{}{
+			"foaf": "http://xmlns.com/foaf/0.1/",
+		},
+		"@id":       "http://example.org/alice",
+		"foaf:name": "Alice",
+	}
+	turtle, err := proc.ToRDF(doc, opts)
+	require.Nil(t, err)
+
+	assert.Equal(t, `@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+<http://example.org/alice> foaf:name "Alice" .
+`, turtle)
+}
@@ -16,7 +16,10 @@ func IsKeyword(key interface{}) bool {
 	return key == "@base" || key == "@context" || key == "@container" || key == "@default" ||
 		key == "@embed" || key == "@explicit" || key == "@graph" || key == "@id" || key == "@index" ||
 		key == "@language" || key == "@list" || key == "@omitDefault" || key == "@reverse" ||
-		key == "@preserve" || key == "@set" || key == "@type" || key == "@value" || key == "@vocab"
+		key == "@preserve" || key == "@set" || key == "@type" || key == "@value" || key == "@vocab" ||
+		key == "@version" || key == "@protected" || key == "@propagate" || key == "@nest" ||
+		key == "@import" || key == "@included" || key == "@json" || key == "@direction" || key == "@none" ||
+		key == "@requireAll"
 }
 
 // DeepCompare returns true if v1 equals v2.
@@ -171,6 +174,27 @@ func IsValue(v interface{}) bool {
 	return isMap && containsValue
 }
 
+// IsGraph returns true if the given value is a graph object: an object with @graph
+// and, optionally, @id and @index as its only members.
+func IsGraph(v interface{}) bool {
+	vMap, isMap := v.(map[string]interface{})
+	if _, containsGraph := vMap["@graph"]; !isMap || !containsGraph {
+		return false
+	}
+	for key := range vMap {
+		if key != "@graph" && key != "@id" && key != "@index" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSimpleGraph returns true if the given value is a graph object without @id.
+func IsSimpleGraph(v interface{}) bool {
+	_, containsID := v.(map[string]interface{})["@id"]
+	return IsGraph(v) && !containsID
+}
+
 // IsBlankNode returns true if the given value is a blank node.
 func IsBlankNodeValue(v interface{}) bool {
 	// Note: A value is a blank node if all of these hold true:
@@ -261,7 +285,12 @@ func RemovePreserve(ctx *Context, input interface{}, opts *JsonLdOptions) (inter
 			result, _ := RemovePreserve(ctx, propVal, opts)
 			container := ctx.GetContainer(prop)
 			resultList, isList := result.([]interface{})
-			if opts.CompactArrays && isList && len(resultList) == 1 && container == "" {
+			if expandedProp, _ := ctx.ExpandIri(prop, false, true, nil, nil); expandedProp == "@graph" {
+				// JSON-LD 1.1: values of @graph in framed output are always arrays
+				if !isList {
+					result = []interface{}{result}
+				}
+			} else if opts.CompactArrays && isList && len(resultList) == 1 && len(container) == 0 {
 				result = resultList[0]
 			}
 			inputMap[prop] = result